@@ -0,0 +1,52 @@
+// Command apikey creates api key for management API, secret is printed once.
+// It may run while server is running, the server picks up new keys on first use
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/shalimski/shortener/config"
+	"github.com/shalimski/shortener/internal/app"
+	"github.com/shalimski/shortener/internal/services"
+	"github.com/shalimski/shortener/pkg/logger"
+)
+
+func main() {
+	owner := flag.String("owner", "", "owner of api key")
+	admin := flag.Bool("admin", false, "allow to manage links of any owner")
+	flag.Parse()
+
+	secret, err := createKey(*owner, *admin)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(secret)
+}
+
+func createKey(owner string, admin bool) (string, error) {
+	if owner == "" {
+		return "", errors.New("owner is required")
+	}
+
+	cfg, err := config.New()
+	if err != nil {
+		return "", err
+	}
+
+	ctx := context.Background()
+	serviceLog := logger.NewLogger()
+
+	keys, closeKeys, err := app.OpenKeys(ctx, cfg, serviceLog)
+	if err != nil {
+		return "", err
+	}
+
+	defer closeKeys() //nolint:errcheck // simple
+
+	return services.NewAuthService(serviceLog, keys).CreateKey(ctx, owner, admin)
+}
@@ -8,16 +8,33 @@ import (
 )
 
 type Config struct {
-	App   App
-	Node  Node
-	HTTP  HTTP
-	Mongo Mongo
-	Redis Redis
+	App       App
+	Node      Node
+	HTTP      HTTP
+	Mongo     Mongo
+	Redis     Redis
+	Cache     Cache
+	Analytics Analytics
+	Auth      Auth
+	Backend   Backend
+	RateLimit RateLimit
 }
 
 type App struct {
-	ShortURLLength int      `env:"SHORT_URL_LENGTH" env-default:"7"`
+	ShortURLLength int      `env:"SHORT_URL_LENGTH" env-default:"7"` // length of random short urls
+	Alphabet       string   `env:"SHORT_URL_ALPHABET" env-default:"abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"`
+	MinLength      int      `env:"SHORT_URL_MIN_LENGTH" env-default:"0"`    // generated short urls are padded to min length
+	MaxLength      int      `env:"SHORT_URL_MAX_LENGTH" env-default:"11"`   // max length of generated short urls and aliases
+	Interval       int      `env:"GENERATOR_INTERVAL" env-default:"100000"` // short urls reserved by node at once, alphabet and interval must not change after first start
+	ObfuscationKey string   `env:"SHORT_URL_KEY" env-default:""`            // secret of permutation making short urls unpredictable, empty keeps them sequential
 	EtcdEndpoints  []string `env:"ETCD_ENDPOINTS" env-default:"http://127.0.0.1:2379"`
+	Dedup          bool     `env:"DEDUP" env-default:"false"`       // return existing short url for already shortened long url
+	CreateAttempts int      `env:"CREATE_ATTEMPTS" env-default:"5"` // generated short urls tried when they are already taken
+	MaxBatchSize   int      `env:"MAX_BATCH_SIZE" env-default:"1000"`
+	RedirectCode   int      `env:"REDIRECT_CODE" env-default:"301"` // default redirect status: 301, 302, 307 or 308
+
+	PasswordAttempts int           `env:"PASSWORD_ATTEMPTS" env-default:"5"`          // wrong passwords of protected url allowed to client per window
+	PasswordWindow   time.Duration `env:"PASSWORD_ATTEMPTS_WINDOW" env-default:"15m"` // window of counted wrong passwords
 }
 
 type Node struct {
@@ -45,6 +62,56 @@ type Redis struct {
 	Password string `env:"REDIS_PASSWORD" env-default:"admin"`
 }
 
+// Cache configures in-process cache, it is the only tier of memory cache backend
+// and the first tier in front of redis
+type Cache struct {
+	LocalSize int           `env:"CACHE_LOCAL_SIZE" env-default:"10000"` // max number of urls, zero disables tier in front of redis
+	LocalTTL  time.Duration `env:"CACHE_LOCAL_TTL" env-default:"1m"`     // max lifetime of url in front of redis
+
+	NotFoundTTL time.Duration `env:"CACHE_NOT_FOUND_TTL" env-default:"30s"` // lifetime of cached missing short url, zero disables caching
+
+	// Bloom filter of existing short urls is kept in process memory and filled from storage on start,
+	// it sees only short urls created by this node, so it is only for single node deployments
+	BloomFilter   bool `env:"BLOOM_FILTER" env-default:"false"`
+	BloomCapacity int  `env:"BLOOM_CAPACITY" env-default:"1000000"` // expected number of short urls
+}
+
+type Analytics struct {
+	BufferSize    int           `env:"ANALYTICS_BUFFER_SIZE" env-default:"10000"`
+	BatchSize     int           `env:"ANALYTICS_BATCH_SIZE" env-default:"500"`
+	FlushInterval time.Duration `env:"ANALYTICS_FLUSH_INTERVAL" env-default:"1s"`
+	CountryHeader string        `env:"ANALYTICS_COUNTRY_HEADER" env-default:"CF-IPCountry"`
+}
+
+type Auth struct {
+	Enabled bool `env:"AUTH_ENABLED" env-default:"true"`
+}
+
+// RateLimit configures token buckets of clients, create and redirect endpoints have own buckets
+// refilled by rate requests per second and holding up to burst requests.
+// Management requests are also limited by address of client before authentication, so api keys can not be brute forced
+type RateLimit struct {
+	Enabled         bool     `env:"RATE_LIMIT_ENABLED" env-default:"true"`
+	CreateRate      float64  `env:"RATE_LIMIT_CREATE_RATE" env-default:"5"`
+	CreateBurst     int      `env:"RATE_LIMIT_CREATE_BURST" env-default:"20"`
+	RedirectRate    float64  `env:"RATE_LIMIT_REDIRECT_RATE" env-default:"50"`
+	RedirectBurst   int      `env:"RATE_LIMIT_REDIRECT_BURST" env-default:"100"`
+	ManagementRate  float64  `env:"RATE_LIMIT_MANAGEMENT_RATE" env-default:"20"`
+	ManagementBurst int      `env:"RATE_LIMIT_MANAGEMENT_BURST" env-default:"50"`
+	TrustedProxies  []string `env:"RATE_LIMIT_TRUSTED_PROXIES" env-default:""` // ips or CIDR ranges of proxies setting X-Forwarded-For
+}
+
+// Backend selects implementations of storage, generator, counter, cache and rate limiter,
+// file storage, file counter and memory cache run without external dependencies
+type Backend struct {
+	Storage   string `env:"STORAGE_BACKEND" env-default:"mongo"`     // mongo or file
+	Generator string `env:"GENERATOR_BACKEND" env-default:"counter"` // counter or random
+	Counter   string `env:"COUNTER_BACKEND" env-default:"etcd"`      // etcd or file, used by counter generator
+	Cache     string `env:"CACHE_BACKEND" env-default:"redis"`       // redis or memory
+	RateLimit string `env:"RATE_LIMIT_BACKEND" env-default:"memory"` // memory limits every node separately, redis shares limits between nodes
+	DataDir   string `env:"DATA_DIR" env-default:"data"`             // directory of file storage and file counter
+}
+
 func New() (*Config, error) {
 	cfg := &Config{}
 
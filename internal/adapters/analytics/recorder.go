@@ -0,0 +1,141 @@
+// Asynchronous click recorder,
+// clicks are buffered in memory and saved to storage in batches by background worker,
+// so redirects never wait for storage
+package analytics
+
+import (
+	"context"
+	"time"
+
+	"github.com/shalimski/shortener/config"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const saveTimeout = 5 * time.Second
+
+// defaults used in place of zero or negative settings
+const (
+	defaultBufferSize    = 10000
+	defaultBatchSize     = 500
+	defaultFlushInterval = time.Second
+)
+
+var _ ports.ClickRecorder = (*Recorder)(nil)
+
+type Recorder struct {
+	log  *logger.Logger
+	repo ports.ClickRepository
+
+	clicks        chan domain.Click
+	batchSize     int
+	flushInterval time.Duration
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewRecorder create instance of Recorder and start background worker,
+// zero or negative settings are replaced by defaults
+func NewRecorder(log *logger.Logger, repo ports.ClickRepository, cfg config.Analytics) *Recorder {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+
+	r := &Recorder{
+		log:           log,
+		repo:          repo,
+		clicks:        make(chan domain.Click, cfg.BufferSize),
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go r.run()
+
+	return r
+}
+
+// Record put click to buffer, click is dropped when buffer is full
+func (r *Recorder) Record(ctx context.Context, click domain.Click) {
+	select {
+	case r.clicks <- click:
+	default:
+		r.log.Error(ctx, "click buffer is full, click dropped", zap.String("shortURL", click.ShortURL))
+	}
+}
+
+// Shutdown stops background worker and saves buffered clicks
+func (r *Recorder) Shutdown() {
+	close(r.quit)
+	<-r.done
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]domain.Click, 0, r.batchSize)
+
+	for {
+		select {
+		case click := <-r.clicks:
+			batch = append(batch, click)
+			if len(batch) >= r.batchSize {
+				batch = r.save(batch)
+			}
+		case <-ticker.C:
+			batch = r.save(batch)
+		case <-r.quit:
+			r.drain(batch)
+
+			return
+		}
+	}
+}
+
+// drain saves all clicks left in buffer
+func (r *Recorder) drain(batch []domain.Click) {
+	for {
+		select {
+		case click := <-r.clicks:
+			batch = append(batch, click)
+			if len(batch) >= r.batchSize {
+				batch = r.save(batch)
+			}
+		default:
+			r.save(batch)
+
+			return
+		}
+	}
+}
+
+// save batch to storage and return emptied batch for reuse
+func (r *Recorder) save(batch []domain.Click) []domain.Click {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+	defer cancel()
+
+	if err := r.repo.Save(ctx, batch); err != nil {
+		r.log.Error(ctx, "failed to save clicks", zap.Int("count", len(batch)), zap.Error(err))
+	}
+
+	return batch[:0]
+}
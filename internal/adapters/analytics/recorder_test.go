@@ -0,0 +1,81 @@
+package analytics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shalimski/shortener/config"
+	"github.com/shalimski/shortener/internal/adapters/analytics"
+	"github.com/shalimski/shortener/internal/domain"
+	mock "github.com/shalimski/shortener/internal/ports/mock"
+	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewTestLogger()
+
+	clicks := []domain.Click{
+		{ShortURL: "abcd", Timestamp: time.Now(), Referrer: "https://google.com"},
+		{ShortURL: "abcd", Timestamp: time.Now(), Country: "NL"},
+		{ShortURL: "abce", Timestamp: time.Now(), UserAgent: "curl/7.79.1"},
+	}
+
+	var saved []domain.Click
+
+	repo := mock.NewMockClickRepository(ctl)
+	repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, batch []domain.Click) error {
+		assert.LessOrEqual(t, len(batch), 2)
+		saved = append(saved, batch...)
+
+		return nil
+	}).Times(2)
+
+	recorder := analytics.NewRecorder(log, repo, config.Analytics{
+		BufferSize:    10,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	for _, click := range clicks {
+		recorder.Record(ctx, click)
+	}
+
+	// buffered clicks are saved on shutdown
+	recorder.Shutdown()
+
+	assert.Equal(t, clicks, saved)
+}
+
+func TestRecorderDefaults(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	saved := make(chan []domain.Click, 1)
+
+	repo := mock.NewMockClickRepository(ctl)
+	repo.EXPECT().Save(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, batch []domain.Click) error {
+		saved <- batch
+
+		return nil
+	})
+
+	// zero settings do not stop recorder from starting
+	recorder := analytics.NewRecorder(logger.NewTestLogger(), repo, config.Analytics{})
+	defer recorder.Shutdown()
+
+	recorder.Record(context.Background(), domain.Click{ShortURL: "abcd", Timestamp: time.Now()})
+
+	select {
+	case batch := <-saved:
+		assert.Len(t, batch, 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("clicks are not flushed by default interval")
+	}
+}
@@ -2,7 +2,9 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"time"
 
 	"github.com/go-redis/redis/v9"
 	"github.com/shalimski/shortener/config"
@@ -10,6 +12,19 @@ import (
 	"github.com/shalimski/shortener/internal/ports"
 )
 
+// notFoundValue marks missing short url, url is never stored as empty value
+const notFoundValue = ""
+
+// cachedURL is cached value of url, it keeps fields url does not marshal
+type cachedURL struct {
+	domain.URL
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+func marshalURL(url domain.URL) ([]byte, error) {
+	return json.Marshal(cachedURL{URL: url, PasswordHash: url.PasswordHash})
+}
+
 type cache struct {
 	rdb *redis.Client
 }
@@ -17,30 +32,92 @@ type cache struct {
 // NewCache create instance of redis server
 func NewCache(cfg *config.Config) ports.Cacher {
 	return &cache{
-		rdb: redis.NewClient(&redis.Options{
-			Addr:     cfg.Redis.DSN,
-			Password: cfg.Redis.Password,
-			DB:       0,
-		}),
+		rdb: newClient(cfg),
 	}
 }
 
-// Set value by key
-func (c *cache) Set(ctx context.Context, key, value string) error {
-	return c.rdb.Set(ctx, key, value, 0).Err()
+func newClient(cfg *config.Config) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.DSN,
+		Password: cfg.Redis.Password,
+		DB:       0,
+	})
+}
+
+// Set url by short url, zero ttl means key has no expiration
+func (c *cache) Set(ctx context.Context, url domain.URL, ttl time.Duration) error {
+	value, err := marshalURL(url)
+	if err != nil {
+		return err
+	}
+
+	return c.rdb.Set(ctx, url.ShortURL, value, ttl).Err()
+}
+
+// Add url by short url only if key does not exist, zero ttl means key has no expiration
+func (c *cache) Add(ctx context.Context, url domain.URL, ttl time.Duration) error {
+	value, err := marshalURL(url)
+	if err != nil {
+		return err
+	}
+
+	return c.rdb.SetNX(ctx, url.ShortURL, value, ttl).Err()
 }
 
-// Get value by key
-func (c *cache) Get(ctx context.Context, key string) (string, error) {
-	url, err := c.rdb.Get(ctx, key).Result()
+// SetMany sets urls by short urls in one pipeline, keys expire with urls
+func (c *cache) SetMany(ctx context.Context, urls []domain.URL) error {
+	now := time.Now()
+
+	_, err := c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, url := range urls {
+			ttl, ok := url.TTL(now)
+			if !ok {
+				continue
+			}
+
+			value, err := marshalURL(url)
+			if err != nil {
+				return err
+			}
+
+			pipe.Set(ctx, url.ShortURL, value, ttl)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// SetNotFound marks short url as missing only if key does not exist, so cached url is never hidden
+func (c *cache) SetNotFound(ctx context.Context, key string, ttl time.Duration) error {
+	return c.rdb.SetNX(ctx, key, notFoundValue, ttl).Err()
+}
+
+// Get url by short url
+func (c *cache) Get(ctx context.Context, key string) (domain.URL, error) {
+	value, err := c.rdb.Get(ctx, key).Bytes()
 	if errors.Is(err, redis.Nil) {
-		return "", domain.ErrNotFound
+		return domain.URL{}, domain.ErrNotFound
 	}
 
 	if err != nil {
-		return "", err
+		return domain.URL{}, err
+	}
+
+	if string(value) == notFoundValue {
+		return domain.URL{}, domain.ErrCachedNotFound
 	}
 
+	var cached cachedURL
+	if err := json.Unmarshal(value, &cached); err != nil {
+		// entries written by previous versions hold only long url
+		return domain.URL{ShortURL: key, LongURL: string(value)}, nil
+	}
+
+	url := cached.URL
+	url.PasswordHash = cached.PasswordHash
+
 	return url, nil
 }
 
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+)
+
+var _ ports.Cacher = (*memoryCache)(nil)
+
+type memoryItem struct {
+	url       domain.URL
+	expiresAt time.Time // zero means item has no expiration
+	notFound  bool      // short url is missing in storage
+}
+
+func (i *memoryItem) isExpired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+}
+
+// memoryCache is in-process LRU cache, least recently used item is evicted when cache is full
+type memoryCache struct {
+	mu     sync.Mutex
+	size   int           // max number of items, zero means cache is unbounded
+	maxTTL time.Duration // max lifetime of item, zero means lifetime is not limited
+	items  map[string]*list.Element
+	order  *list.List // front is most recently used
+}
+
+// NewMemoryCache create instance of in-process cache holding up to size items,
+// every item lives no longer than maxTTL, zero disables a limit
+func NewMemoryCache(size int, maxTTL time.Duration) ports.Cacher {
+	return &memoryCache{
+		size:   size,
+		maxTTL: maxTTL,
+		items:  make(map[string]*list.Element),
+		order:  list.New(),
+	}
+}
+
+func (c *memoryCache) put(url domain.URL, ttl time.Duration, now time.Time) {
+	c.putItem(&memoryItem{url: url}, ttl, now)
+}
+
+// putItem adds or replaces item and evicts least recently used items above size
+func (c *memoryCache) putItem(item *memoryItem, ttl time.Duration, now time.Time) {
+	url := item.url
+
+	if c.maxTTL > 0 && (ttl <= 0 || ttl > c.maxTTL) {
+		ttl = c.maxTTL
+	}
+
+	if ttl > 0 {
+		item.expiresAt = now.Add(ttl)
+	}
+
+	if el, ok := c.items[url.ShortURL]; ok {
+		el.Value = item
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.items[url.ShortURL] = c.order.PushFront(item)
+
+	for c.size > 0 && c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *memoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*memoryItem).url.ShortURL) //nolint:forcetypeassert // list holds only items
+}
+
+// Set url by short url, zero ttl means key has no expiration
+func (c *memoryCache) Set(ctx context.Context, url domain.URL, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.put(url, ttl, time.Now())
+
+	return nil
+}
+
+// Add url by short url only if key does not exist, zero ttl means key has no expiration
+func (c *memoryCache) Add(ctx context.Context, url domain.URL, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if el, ok := c.items[url.ShortURL]; ok {
+		item := el.Value.(*memoryItem) //nolint:forcetypeassert // list holds only items
+		if !item.notFound && !item.isExpired(now) {
+			return nil
+		}
+	}
+
+	c.put(url, ttl, now)
+
+	return nil
+}
+
+// SetNotFound marks short url as missing only if key does not exist, so cached url is never hidden
+func (c *memoryCache) SetNotFound(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	if el, ok := c.items[key]; ok && !el.Value.(*memoryItem).isExpired(now) { //nolint:forcetypeassert // list holds only items
+		return nil
+	}
+
+	c.putItem(&memoryItem{url: domain.URL{ShortURL: key}, notFound: true}, ttl, now)
+
+	return nil
+}
+
+// SetMany sets urls by short urls, keys expire with urls
+func (c *memoryCache) SetMany(ctx context.Context, urls []domain.URL) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	for _, url := range urls {
+		ttl, ok := url.TTL(now)
+		if !ok {
+			continue
+		}
+
+		c.put(url, ttl, now)
+	}
+
+	return nil
+}
+
+// Get url by short url, expired item is removed
+func (c *memoryCache) Get(ctx context.Context, key string) (domain.URL, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return domain.URL{}, domain.ErrNotFound
+	}
+
+	item := el.Value.(*memoryItem) //nolint:forcetypeassert // list holds only items
+	if item.isExpired(time.Now()) {
+		c.remove(el)
+
+		return domain.URL{}, domain.ErrNotFound
+	}
+
+	c.order.MoveToFront(el)
+
+	if item.notFound {
+		return domain.URL{}, domain.ErrCachedNotFound
+	}
+
+	return item.url, nil
+}
+
+// Delete value by key
+func (c *memoryCache) Del(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shalimski/shortener/internal/adapters/cache"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(0, 0)
+
+	url := domain.URL{ShortURL: "a", LongURL: "https://a.com"}
+
+	require.NoError(t, c.Set(ctx, url, 0))
+	require.NoError(t, c.Add(ctx, domain.URL{ShortURL: "a", LongURL: "https://b.com"}, 0))
+
+	got, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, url, got, "add does not overwrite existing key")
+
+	require.NoError(t, c.Del(ctx, "a"))
+
+	_, err = c.Get(ctx, "a")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.NoError(t, c.Set(ctx, url, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = c.Get(ctx, "a")
+	assert.ErrorIs(t, err, domain.ErrNotFound, "key expired")
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(2, 0)
+
+	require.NoError(t, c.Set(ctx, domain.URL{ShortURL: "a"}, 0))
+	require.NoError(t, c.Set(ctx, domain.URL{ShortURL: "b"}, 0))
+
+	// a becomes most recently used
+	_, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, domain.URL{ShortURL: "c"}, 0))
+
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, domain.ErrNotFound, "least recently used key evicted")
+
+	_, err = c.Get(ctx, "a")
+	assert.NoError(t, err)
+
+	_, err = c.Get(ctx, "c")
+	assert.NoError(t, err)
+}
+
+func TestMemoryCacheMaxTTL(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(0, time.Millisecond)
+
+	require.NoError(t, c.Set(ctx, domain.URL{ShortURL: "a"}, 0))
+	require.NoError(t, c.Set(ctx, domain.URL{ShortURL: "b"}, time.Hour))
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := c.Get(ctx, "a")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestMemoryCacheNotFound(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewMemoryCache(0, 0)
+
+	require.NoError(t, c.SetNotFound(ctx, "a", time.Minute))
+
+	_, err := c.Get(ctx, "a")
+	assert.ErrorIs(t, err, domain.ErrCachedNotFound)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	url := domain.URL{ShortURL: "a", LongURL: "https://a.com"}
+	require.NoError(t, c.Add(ctx, url, 0))
+
+	got, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, url, got, "add replaces missing mark")
+
+	require.NoError(t, c.SetNotFound(ctx, "a", time.Minute))
+
+	got, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, url, got, "missing mark never hides url")
+}
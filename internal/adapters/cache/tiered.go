@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/shalimski/shortener/config"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/shalimski/shortener/pkg/randomstring"
+	"go.uber.org/zap"
+)
+
+const (
+	invalidationChannel = "shortener:invalidate"
+	nodeIDLength        = 16
+)
+
+var _ ports.Cacher = (*TieredCache)(nil)
+
+// invalidation is published when url is rewritten or deleted on one of nodes
+type invalidation struct {
+	Node string `json:"node"`
+	Key  string `json:"key"`
+}
+
+// TieredCache checks in-process cache before redis.
+// Nodes drop local entries of rewritten and deleted urls by messages of redis pub/sub,
+// messages missed while redis is unreachable are covered by short lifetime of local entries
+type TieredCache struct {
+	log    *logger.Logger
+	local  ports.Cacher
+	remote ports.Cacher
+	rdb    *redis.Client
+	sub    *redis.PubSub
+	node   string // id of node to skip own messages
+	done   chan struct{}
+}
+
+// NewTieredCache create instance of two-tier cache and start listening invalidations
+func NewTieredCache(cfg *config.Config, log *logger.Logger) *TieredCache {
+	rdb := newClient(cfg)
+
+	c := &TieredCache{
+		log:    log,
+		local:  NewMemoryCache(cfg.Cache.LocalSize, cfg.Cache.LocalTTL),
+		remote: &cache{rdb: rdb},
+		rdb:    rdb,
+		sub:    rdb.Subscribe(context.Background(), invalidationChannel),
+		node:   randomstring.New(nodeIDLength),
+		done:   make(chan struct{}),
+	}
+
+	go c.listen()
+
+	return c
+}
+
+// Close stops listening invalidations
+func (c *TieredCache) Close() error {
+	err := c.sub.Close()
+	<-c.done
+
+	return err
+}
+
+func (c *TieredCache) listen() {
+	defer close(c.done)
+
+	ctx := context.Background()
+
+	for msg := range c.sub.Channel() {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(msg.Payload), &inv); err != nil {
+			c.log.Error(ctx, "failed to decode cache invalidation", zap.Error(err))
+
+			continue
+		}
+
+		if inv.Node == c.node {
+			continue
+		}
+
+		c.local.Del(ctx, inv.Key) //nolint:errcheck // memory cache never fails
+	}
+}
+
+// invalidate drops key from local caches of other nodes
+func (c *TieredCache) invalidate(ctx context.Context, key string) error {
+	msg, err := json.Marshal(invalidation{Node: c.node, Key: key})
+	if err != nil {
+		return err
+	}
+
+	return c.rdb.Publish(ctx, invalidationChannel, msg).Err()
+}
+
+// Set url in both tiers, other nodes drop their local entries
+func (c *TieredCache) Set(ctx context.Context, url domain.URL, ttl time.Duration) error {
+	if err := c.remote.Set(ctx, url, ttl); err != nil {
+		return err
+	}
+
+	c.local.Set(ctx, url, ttl) //nolint:errcheck // memory cache never fails
+
+	return c.invalidate(ctx, url.ShortURL)
+}
+
+// Add url to redis only if key does not exist, local tier is filled by Get from redis
+func (c *TieredCache) Add(ctx context.Context, url domain.URL, ttl time.Duration) error {
+	return c.remote.Add(ctx, url, ttl)
+}
+
+// SetMany sets new urls in both tiers
+func (c *TieredCache) SetMany(ctx context.Context, urls []domain.URL) error {
+	if err := c.remote.SetMany(ctx, urls); err != nil {
+		return err
+	}
+
+	return c.local.SetMany(ctx, urls)
+}
+
+// SetNotFound marks short url as missing in redis only, because local tiers of other nodes
+// are not invalidated when short url is created
+func (c *TieredCache) SetNotFound(ctx context.Context, key string, ttl time.Duration) error {
+	return c.remote.SetNotFound(ctx, key, ttl)
+}
+
+// Get url from local tier, on miss from redis
+func (c *TieredCache) Get(ctx context.Context, key string) (domain.URL, error) {
+	if url, err := c.local.Get(ctx, key); err == nil {
+		return url, nil
+	}
+
+	url, err := c.remote.Get(ctx, key)
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	if ttl, ok := url.TTL(time.Now()); ok {
+		c.local.Set(ctx, url, ttl) //nolint:errcheck // memory cache never fails
+	}
+
+	return url, nil
+}
+
+// Del url from both tiers, other nodes drop their local entries
+func (c *TieredCache) Del(ctx context.Context, key string) error {
+	if err := c.remote.Del(ctx, key); err != nil {
+		return err
+	}
+
+	c.local.Del(ctx, key) //nolint:errcheck // memory cache never fails
+
+	return c.invalidate(ctx, key)
+}
@@ -0,0 +1,79 @@
+package auditrepo
+
+import (
+	"context"
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection of audit events
+const Collection = "audit"
+
+var _ ports.AuditRepository = (*auditRepo)(nil)
+
+// eventDocument is stored shape of audit event
+type eventDocument struct {
+	ShortURL string    `bson:"shorturl"`
+	Action   string    `bson:"action"`
+	Actor    string    `bson:"actor"`
+	LongURL  string    `bson:"longurl,omitempty"`
+	At       time.Time `bson:"at"`
+}
+
+// repository of audit events, events are only inserted
+type auditRepo struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepo create instance of auditRepo, indexes of audit collection are created by migrations
+func NewAuditRepo(db *mongo.Database) ports.AuditRepository {
+	return &auditRepo{
+		collection: db.Collection(Collection),
+	}
+}
+
+// Append event to DB
+func (r *auditRepo) Append(ctx context.Context, event domain.AuditEvent) error {
+	_, err := r.collection.InsertOne(ctx, eventDocument{
+		ShortURL: event.ShortURL,
+		Action:   string(event.Action),
+		Actor:    event.Actor,
+		LongURL:  event.LongURL,
+		At:       event.At,
+	})
+
+	return err
+}
+
+// List events of short url in order of appending
+func (r *auditRepo) List(ctx context.Context, shortURL string) ([]domain.AuditEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"shorturl": shortURL}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []eventDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.AuditEvent, 0, len(docs))
+	for _, doc := range docs {
+		events = append(events, domain.AuditEvent{
+			ShortURL: doc.ShortURL,
+			Action:   domain.AuditAction(doc.Action),
+			Actor:    doc.Actor,
+			LongURL:  doc.LongURL,
+			At:       doc.At,
+		})
+	}
+
+	return events, nil
+}
@@ -0,0 +1,55 @@
+package clickrepo
+
+import (
+	"github.com/shalimski/shortener/internal/domain"
+)
+
+// names of fields of counter documents used in queries and indexes
+const (
+	fieldShortURL = "shorturl"
+	fieldDay      = "day"
+	fieldReferrer = "referrer"
+	fieldClicks   = "clicks"
+)
+
+// dailyDocument counts clicks of short url in one day
+type dailyDocument struct {
+	ShortURL string `bson:"shorturl"`
+	Day      string `bson:"day"` // YYYY-MM-DD in UTC
+	Clicks   int64  `bson:"clicks"`
+}
+
+// referrerDocument counts clicks of short url from one referrer
+type referrerDocument struct {
+	ShortURL string `bson:"shorturl"`
+	Referrer string `bson:"referrer"` // empty for clicks without referrer
+	Clicks   int64  `bson:"clicks"`
+}
+
+// countClicks groups batch of clicks by counters they increment
+func countClicks(clicks []domain.Click) (daily []dailyDocument, referrers []referrerDocument) {
+	dayIndex := make(map[dailyDocument]int)
+	referrerIndex := make(map[referrerDocument]int)
+
+	for _, click := range clicks {
+		day := dailyDocument{ShortURL: click.ShortURL, Day: click.Timestamp.UTC().Format(domain.DayLayout)}
+		if i, ok := dayIndex[day]; ok {
+			daily[i].Clicks++
+		} else {
+			dayIndex[day] = len(daily)
+			day.Clicks = 1
+			daily = append(daily, day)
+		}
+
+		ref := referrerDocument{ShortURL: click.ShortURL, Referrer: click.Referrer}
+		if i, ok := referrerIndex[ref]; ok {
+			referrers[i].Clicks++
+		} else {
+			referrerIndex[ref] = len(referrers)
+			ref.Clicks = 1
+			referrers = append(referrers, ref)
+		}
+	}
+
+	return daily, referrers
+}
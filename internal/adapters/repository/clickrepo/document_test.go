@@ -0,0 +1,32 @@
+package clickrepo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountClicks(t *testing.T) {
+	day := time.Date(2022, 10, 1, 23, 0, 0, 0, time.UTC)
+
+	daily, referrers := countClicks([]domain.Click{
+		{ShortURL: "abcd", Timestamp: day, Referrer: "https://google.com"},
+		{ShortURL: "abcd", Timestamp: day.Add(30 * time.Minute), Referrer: "https://google.com"},
+		{ShortURL: "abcd", Timestamp: day.Add(2 * time.Hour)},
+		{ShortURL: "efgh", Timestamp: day, Referrer: "https://google.com"},
+	})
+
+	assert.Equal(t, []dailyDocument{
+		{ShortURL: "abcd", Day: "2022-10-01", Clicks: 2},
+		{ShortURL: "abcd", Day: "2022-10-02", Clicks: 1},
+		{ShortURL: "efgh", Day: "2022-10-01", Clicks: 1},
+	}, daily)
+
+	assert.Equal(t, []referrerDocument{
+		{ShortURL: "abcd", Referrer: "https://google.com", Clicks: 2},
+		{ShortURL: "abcd", Referrer: "", Clicks: 1},
+		{ShortURL: "efgh", Referrer: "https://google.com", Clicks: 1},
+	}, referrers)
+}
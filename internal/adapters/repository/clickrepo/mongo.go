@@ -0,0 +1,116 @@
+package clickrepo
+
+import (
+	"context"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collections of click counters
+const (
+	DailyCollection    = "clicks_daily"
+	ReferrerCollection = "clicks_referrers"
+)
+
+var _ ports.ClickRepository = (*clickRepo)(nil)
+
+// repository of click counters, clicks are not stored one by one
+type clickRepo struct {
+	daily     *mongo.Collection
+	referrers *mongo.Collection
+}
+
+// NewClickRepo create instance of clickRepo, indexes of counter collections are created by migrations
+func NewClickRepo(db *mongo.Database) ports.ClickRepository {
+	return &clickRepo{
+		daily:     db.Collection(DailyCollection),
+		referrers: db.Collection(ReferrerCollection),
+	}
+}
+
+// Save adds batch of clicks to counters of days and referrers, every counter is incremented once per batch
+func (r *clickRepo) Save(ctx context.Context, clicks []domain.Click) error {
+	daily, referrers := countClicks(clicks)
+
+	dailyModels := make([]mongo.WriteModel, 0, len(daily))
+	for _, doc := range daily {
+		dailyModels = append(dailyModels, incModel(bson.M{fieldShortURL: doc.ShortURL, fieldDay: doc.Day}, doc.Clicks))
+	}
+
+	referrerModels := make([]mongo.WriteModel, 0, len(referrers))
+	for _, doc := range referrers {
+		referrerModels = append(referrerModels, incModel(bson.M{fieldShortURL: doc.ShortURL, fieldReferrer: doc.Referrer}, doc.Clicks))
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+
+	if len(dailyModels) != 0 {
+		if _, err := r.daily.BulkWrite(ctx, dailyModels, opts); err != nil {
+			return err
+		}
+	}
+
+	if len(referrerModels) != 0 {
+		if _, err := r.referrers.BulkWrite(ctx, referrerModels, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// incModel increments clicks of counter selected by filter, missing counter is created
+func incModel(filter bson.M, clicks int64) mongo.WriteModel {
+	return mongo.NewUpdateOneModel().
+		SetFilter(filter).
+		SetUpdate(bson.M{"$inc": bson.M{fieldClicks: clicks}}).
+		SetUpsert(true)
+}
+
+// Stats reads counters of short url: total, by day and top referrers
+func (r *clickRepo) Stats(ctx context.Context, shortURL string) (domain.Stats, error) {
+	stats := domain.Stats{
+		ShortURL:  shortURL,
+		Daily:     []domain.DailyStat{},
+		Referrers: []domain.ReferrerStat{},
+	}
+
+	filter := bson.M{fieldShortURL: shortURL}
+
+	cursor, err := r.daily.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: fieldDay, Value: 1}}))
+	if err != nil {
+		return domain.Stats{}, err
+	}
+
+	var days []dailyDocument
+	if err := cursor.All(ctx, &days); err != nil {
+		return domain.Stats{}, err
+	}
+
+	for _, d := range days {
+		stats.Total += d.Clicks
+		stats.Daily = append(stats.Daily, domain.DailyStat{Day: d.Day, Clicks: d.Clicks})
+	}
+
+	cursor, err = r.referrers.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: fieldClicks, Value: -1}, {Key: fieldReferrer, Value: 1}}).
+		SetLimit(domain.MaxReferrers))
+	if err != nil {
+		return domain.Stats{}, err
+	}
+
+	var referrers []referrerDocument
+	if err := cursor.All(ctx, &referrers); err != nil {
+		return domain.Stats{}, err
+	}
+
+	for _, ref := range referrers {
+		stats.Referrers = append(stats.Referrers, domain.ReferrerStat{Referrer: ref.Referrer, Clicks: ref.Clicks})
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,80 @@
+package filedb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/wal"
+)
+
+var _ ports.AuditRepository = (*auditRepo)(nil)
+
+// auditRepo keeps audit events in memory, events are only appended so log is never compacted
+type auditRepo struct {
+	mu     sync.RWMutex
+	events map[string][]domain.AuditEvent
+	log    *wal.Log
+}
+
+func openAuditRepo(path string) (*auditRepo, error) {
+	log, err := wal.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &auditRepo{
+		events: make(map[string][]domain.AuditEvent),
+		log:    log,
+	}
+
+	err = log.Replay(func(data []byte) error {
+		var event domain.AuditEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("failed to decode audit event: %w", err)
+		}
+
+		r.events[event.ShortURL] = append(r.events[event.ShortURL], event)
+
+		return nil
+	})
+	if err != nil {
+		log.Close() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *auditRepo) close() error {
+	return r.log.Close()
+}
+
+// Append event to log and memory
+func (r *auditRepo) Append(ctx context.Context, event domain.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.log.Append(event); err != nil {
+		return err
+	}
+
+	r.events[event.ShortURL] = append(r.events[event.ShortURL], event)
+
+	return nil
+}
+
+// List events of short url in order of appending
+func (r *auditRepo) List(ctx context.Context, shortURL string) ([]domain.AuditEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]domain.AuditEvent, len(r.events[shortURL]))
+	copy(events, r.events[shortURL])
+
+	return events, nil
+}
@@ -0,0 +1,227 @@
+package filedb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/wal"
+)
+
+var _ ports.ClickRepository = (*clickRepo)(nil)
+
+// maxTrackedReferrers bounds referrers counted per short url, the least clicked one is dropped for a new one,
+// so top referrers are approximate for urls with many rare referrers
+const maxTrackedReferrers = 10 * domain.MaxReferrers
+
+// clickRecord adds clicks of short url to counters by day and referrer
+type clickRecord struct {
+	ShortURL  string           `json:"short_url"`
+	Daily     map[string]int64 `json:"daily"`
+	Referrers map[string]int64 `json:"referrers"`
+}
+
+// clickStats is aggregated clicks of one short url
+type clickStats struct {
+	total     int64
+	daily     map[string]int64
+	referrers map[string]int64
+}
+
+// clickRepo appends counters of saved batches to log and keeps only aggregates in memory,
+// log is compacted to one record per short url on open
+type clickRepo struct {
+	mu    sync.RWMutex
+	stats map[string]*clickStats
+	log   *wal.Log
+}
+
+func openClickRepo(path string) (*clickRepo, error) {
+	log, err := wal.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &clickRepo{
+		stats: make(map[string]*clickStats),
+		log:   log,
+	}
+
+	err = log.Replay(func(data []byte) error {
+		var rec clickRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return fmt.Errorf("failed to decode clicks: %w", err)
+		}
+
+		r.add(rec)
+
+		return nil
+	})
+	if err != nil {
+		log.Close() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	shortURLs := make([]string, 0, len(r.stats))
+	for shortURL := range r.stats {
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	sort.Strings(shortURLs)
+
+	records := make([]any, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		s := r.stats[shortURL]
+		records = append(records, clickRecord{ShortURL: shortURL, Daily: s.daily, Referrers: s.referrers})
+	}
+
+	if err := log.Rewrite(records); err != nil {
+		log.Close() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *clickRepo) close() error {
+	return r.log.Close()
+}
+
+func (r *clickRepo) add(rec clickRecord) {
+	s, ok := r.stats[rec.ShortURL]
+	if !ok {
+		s = &clickStats{
+			daily:     make(map[string]int64),
+			referrers: make(map[string]int64),
+		}
+		r.stats[rec.ShortURL] = s
+	}
+
+	for day, clicks := range rec.Daily {
+		s.total += clicks
+		s.daily[day] += clicks
+	}
+
+	for referrer, clicks := range rec.Referrers {
+		s.addReferrer(referrer, clicks)
+	}
+}
+
+// addReferrer counts clicks of referrer, the least clicked referrer is dropped when too many are tracked
+func (s *clickStats) addReferrer(referrer string, clicks int64) {
+	if _, ok := s.referrers[referrer]; !ok && len(s.referrers) >= maxTrackedReferrers {
+		var (
+			least string
+			found bool
+		)
+
+		for ref, n := range s.referrers {
+			if !found || n < s.referrers[least] || (n == s.referrers[least] && ref > least) {
+				least, found = ref, true
+			}
+		}
+
+		delete(s.referrers, least)
+	}
+
+	s.referrers[referrer] += clicks
+}
+
+// countClicks groups batch of clicks by short url, records keep order of first clicks of short urls
+func countClicks(clicks []domain.Click) []clickRecord {
+	records := make([]clickRecord, 0)
+	index := make(map[string]int)
+
+	for _, click := range clicks {
+		i, ok := index[click.ShortURL]
+		if !ok {
+			i = len(records)
+			index[click.ShortURL] = i
+			records = append(records, clickRecord{
+				ShortURL:  click.ShortURL,
+				Daily:     make(map[string]int64),
+				Referrers: make(map[string]int64),
+			})
+		}
+
+		records[i].Daily[click.Timestamp.UTC().Format(domain.DayLayout)]++
+		records[i].Referrers[click.Referrer]++
+	}
+
+	return records
+}
+
+// Save appends counters of batch of clicks to log
+func (r *clickRepo) Save(ctx context.Context, clicks []domain.Click) error {
+	counted := countClicks(clicks)
+
+	records := make([]any, 0, len(counted))
+	for _, rec := range counted {
+		records = append(records, rec)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.log.Append(records...); err != nil {
+		return err
+	}
+
+	for _, rec := range counted {
+		r.add(rec)
+	}
+
+	return nil
+}
+
+// Stats returns aggregated clicks of short url: total, by day and top referrers
+func (r *clickRepo) Stats(ctx context.Context, shortURL string) (domain.Stats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := domain.Stats{
+		ShortURL:  shortURL,
+		Daily:     []domain.DailyStat{},
+		Referrers: []domain.ReferrerStat{},
+	}
+
+	s, ok := r.stats[shortURL]
+	if !ok {
+		return stats, nil
+	}
+
+	stats.Total = s.total
+
+	for day, clicks := range s.daily {
+		stats.Daily = append(stats.Daily, domain.DailyStat{Day: day, Clicks: clicks})
+	}
+
+	for referrer, clicks := range s.referrers {
+		stats.Referrers = append(stats.Referrers, domain.ReferrerStat{Referrer: referrer, Clicks: clicks})
+	}
+
+	sort.Slice(stats.Daily, func(i, j int) bool {
+		return stats.Daily[i].Day < stats.Daily[j].Day
+	})
+
+	sort.Slice(stats.Referrers, func(i, j int) bool {
+		a, b := stats.Referrers[i], stats.Referrers[j]
+		if a.Clicks != b.Clicks {
+			return a.Clicks > b.Clicks
+		}
+
+		return a.Referrer < b.Referrer
+	})
+
+	if len(stats.Referrers) > domain.MaxReferrers {
+		stats.Referrers = stats.Referrers[:domain.MaxReferrers]
+	}
+
+	return stats, nil
+}
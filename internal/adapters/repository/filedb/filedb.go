@@ -0,0 +1,139 @@
+// Package filedb implements embedded repositories persisted to local files,
+// data is kept in memory and every change is written to write-ahead log before it is acknowledged
+package filedb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/filelock"
+)
+
+const (
+	urlLog   = "urls.wal"
+	clickLog = "clicks.wal"
+	keyLog   = "apikeys.wal"
+	auditLog = "audit.wal"
+
+	lockFile = "LOCK"
+)
+
+// DB is a set of repositories stored in one data directory
+type DB struct {
+	urls   *urlRepo
+	clicks *clickRepo
+	keys   *keyRepo
+	audit  *auditRepo
+
+	lock *filelock.Lock
+}
+
+// Open loads repositories from data directory, creates it if not exist.
+// Directory is locked until Close, because logs are compacted on open and must not be shared by servers
+func Open(dir string) (*DB, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data dir: %w", err)
+	}
+
+	lock, err := filelock.TryLock(filepath.Join(dir, lockFile))
+	if errors.Is(err, filelock.ErrLocked) {
+		return nil, fmt.Errorf("data dir %s is used by another process", dir)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := openRepos(dir)
+	if err != nil {
+		lock.Unlock() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	db.lock = lock
+
+	return db, nil
+}
+
+// OpenKeys loads only api key repository without locking data directory, it is used to create keys
+// while server is running. Key log is never compacted, so records appended by both processes are kept
+// and the server finds new keys on first use
+func OpenKeys(dir string) (repo ports.APIKeyRepository, closeFn func() error, err error) {
+	keys, err := openKeyRepo(filepath.Join(dir, keyLog))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open api key repository: %w", err)
+	}
+
+	return keys, keys.close, nil
+}
+
+func openRepos(dir string) (*DB, error) {
+	urls, err := openURLRepo(filepath.Join(dir, urlLog))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open url repository: %w", err)
+	}
+
+	clicks, err := openClickRepo(filepath.Join(dir, clickLog))
+	if err != nil {
+		urls.close() //nolint:errcheck // already failed
+
+		return nil, fmt.Errorf("failed to open click repository: %w", err)
+	}
+
+	keys, err := openKeyRepo(filepath.Join(dir, keyLog))
+	if err != nil {
+		urls.close()   //nolint:errcheck // already failed
+		clicks.close() //nolint:errcheck // already failed
+
+		return nil, fmt.Errorf("failed to open api key repository: %w", err)
+	}
+
+	audit, err := openAuditRepo(filepath.Join(dir, auditLog))
+	if err != nil {
+		urls.close()   //nolint:errcheck // already failed
+		clicks.close() //nolint:errcheck // already failed
+		keys.close()   //nolint:errcheck // already failed
+
+		return nil, fmt.Errorf("failed to open audit repository: %w", err)
+	}
+
+	return &DB{
+		urls:   urls,
+		clicks: clicks,
+		keys:   keys,
+		audit:  audit,
+	}, nil
+}
+
+func (db *DB) URLs() ports.Repository {
+	return db.urls
+}
+
+func (db *DB) Clicks() ports.ClickRepository {
+	return db.clicks
+}
+
+func (db *DB) Keys() ports.APIKeyRepository {
+	return db.keys
+}
+
+func (db *DB) Audit() ports.AuditRepository {
+	return db.audit
+}
+
+// Close log files of all repositories and unlock data directory
+func (db *DB) Close() error {
+	var firstErr error
+
+	for _, closeFn := range []func() error{db.urls.close, db.clicks.close, db.keys.close, db.audit.close, db.lock.Unlock} {
+		if err := closeFn(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,202 @@
+package filedb_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shalimski/shortener/internal/adapters/repository/filedb"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	expired := time.Now().Add(-time.Hour)
+
+	db, err := filedb.Open(dir)
+	require.NoError(t, err)
+
+	urls := db.URLs()
+
+	require.NoError(t, urls.Create(ctx, domain.URL{ShortURL: "a", LongURL: "https://a.com", IdempotencyKey: "k1", Owner: "o", PasswordHash: "h"}))
+	assert.ErrorIs(t, urls.Create(ctx, domain.URL{ShortURL: "a", LongURL: "https://b.com"}), domain.ErrAlreadyExists)
+
+	errs := urls.CreateMany(ctx, []domain.URL{
+		{ShortURL: "b", LongURL: "https://b.com"},
+		{ShortURL: "a", LongURL: "https://c.com"},
+		{ShortURL: "c", LongURL: "https://c.com"},
+		{ShortURL: "d", LongURL: "https://d.com", ExpiresAt: &expired},
+	})
+	assert.NoError(t, errs[0])
+	assert.ErrorIs(t, errs[1], domain.ErrAlreadyExists)
+	assert.NoError(t, errs[2])
+	assert.NoError(t, errs[3])
+
+	require.NoError(t, urls.Update(ctx, domain.URL{ShortURL: "b", LongURL: "https://b.org"}))
+	require.NoError(t, urls.Delete(ctx, "c", "o"))
+
+	day := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Clicks().Save(ctx, []domain.Click{
+		{ShortURL: "a", Timestamp: day, Referrer: "x"},
+		{ShortURL: "a", Timestamp: day.Add(24 * time.Hour), Referrer: "y"},
+		{ShortURL: "a", Timestamp: day.Add(24 * time.Hour), Referrer: "y"},
+	}))
+
+	require.NoError(t, db.Keys().Create(ctx, domain.APIKey{Hash: "h", Owner: "o"}))
+
+	events := []domain.AuditEvent{
+		{ShortURL: "c", Action: domain.AuditCreated, Actor: "o", LongURL: "https://c.com", At: day},
+		{ShortURL: "c", Action: domain.AuditDeleted, Actor: "o", LongURL: "https://c.com", At: day.Add(time.Hour)},
+	}
+	for _, event := range events {
+		require.NoError(t, db.Audit().Append(ctx, event))
+	}
+
+	require.NoError(t, db.Close())
+
+	// state is restored from log
+	db, err = filedb.Open(dir)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	urls = db.URLs()
+
+	url, err := urls.FindByIdempotencyKey(ctx, "o", "k1")
+	require.NoError(t, err)
+	assert.Equal(t, "a", url.ShortURL)
+	assert.Equal(t, "h", url.PasswordHash)
+
+	url, err = urls.Find(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "https://b.org", url.LongURL)
+
+	url, err = urls.Find(ctx, "c")
+	require.NoError(t, err)
+	assert.True(t, url.IsDeleted(), "deleted url is kept as tombstone")
+	assert.Equal(t, "o", url.DeletedBy)
+
+	_, err = urls.Find(ctx, "d")
+	assert.ErrorIs(t, err, domain.ErrNotFound, "expired url is dropped")
+
+	stats, err := db.Clicks().Stats(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Stats{
+		ShortURL:  "a",
+		Total:     3,
+		Daily:     []domain.DailyStat{{Day: "2022-10-01", Clicks: 1}, {Day: "2022-10-02", Clicks: 2}},
+		Referrers: []domain.ReferrerStat{{Referrer: "y", Clicks: 2}, {Referrer: "x", Clicks: 1}},
+	}, stats)
+
+	key, err := db.Keys().Find(ctx, "h")
+	require.NoError(t, err)
+	assert.Equal(t, domain.APIKey{Hash: "h", Owner: "o"}, key)
+	assert.ErrorIs(t, db.Keys().Create(ctx, domain.APIKey{Hash: "h"}), domain.ErrAlreadyExists)
+
+	history, err := db.Audit().List(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, events, history)
+}
+
+func TestTopReferrers(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := filedb.Open(t.TempDir())
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	clicks := make([]domain.Click, 0)
+	for i := 0; i < domain.MaxReferrers+5; i++ {
+		for j := 0; j <= i; j++ {
+			clicks = append(clicks, domain.Click{ShortURL: "a", Timestamp: time.Now(), Referrer: fmt.Sprint(i)})
+		}
+	}
+
+	require.NoError(t, db.Clicks().Save(ctx, clicks))
+
+	stats, err := db.Clicks().Stats(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(clicks)), stats.Total)
+	assert.Len(t, stats.Referrers, domain.MaxReferrers)
+	assert.Equal(t, fmt.Sprint(domain.MaxReferrers+4), stats.Referrers[0].Referrer, "most clicked referrer is first")
+}
+
+func TestSharedDataDir(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := filedb.Open(dir)
+	require.NoError(t, err)
+
+	_, err = filedb.Open(dir)
+	assert.Error(t, err, "data dir is locked by running server")
+
+	// key created by cmd/apikey while server is running
+	keys, closeKeys, err := filedb.OpenKeys(dir)
+	require.NoError(t, err)
+	require.NoError(t, keys.Create(ctx, domain.APIKey{Hash: "h", Owner: "o"}))
+	require.NoError(t, closeKeys())
+
+	key, err := db.Keys().Find(ctx, "h")
+	require.NoError(t, err)
+	assert.Equal(t, "o", key.Owner)
+
+	_, err = db.Keys().Find(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.NoError(t, db.Close())
+
+	db, err = filedb.Open(dir)
+	require.NoError(t, err, "data dir is unlocked on close")
+	require.NoError(t, db.Close())
+}
+
+func TestCompactClicks(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	day := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	db, err := filedb.Open(dir)
+	require.NoError(t, err)
+
+	// every click has own referrer, only the most clicked ones are kept
+	for i := 0; i < 1000; i++ {
+		clicks := []domain.Click{
+			{ShortURL: "a", Timestamp: day, Referrer: "https://popular.com"},
+			{ShortURL: "a", Timestamp: day.Add(24 * time.Hour), Referrer: fmt.Sprint("https://", i, ".com")},
+			{ShortURL: "b", Timestamp: day},
+		}
+		require.NoError(t, db.Clicks().Save(ctx, clicks))
+	}
+
+	require.NoError(t, db.Close())
+
+	db, err = filedb.Open(dir)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "clicks.wal"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(data, []byte("\n")), "log has one record per short url")
+	assert.Less(t, len(data), 20000, "log does not grow with clicks")
+
+	stats, err := db.Clicks().Stats(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), stats.Total)
+	assert.Equal(t, []domain.DailyStat{{Day: "2022-10-01", Clicks: 1000}, {Day: "2022-10-02", Clicks: 1000}}, stats.Daily)
+	assert.Equal(t, domain.ReferrerStat{Referrer: "https://popular.com", Clicks: 1000}, stats.Referrers[0])
+
+	stats, err = db.Clicks().Stats(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), stats.Total)
+	assert.Equal(t, []domain.ReferrerStat{{Referrer: "", Clicks: 1000}}, stats.Referrers)
+}
@@ -0,0 +1,108 @@
+package filedb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/wal"
+)
+
+var _ ports.APIKeyRepository = (*keyRepo)(nil)
+
+// keyRecord is api key written to log
+type keyRecord struct {
+	Hash string        `json:"hash"` // key does not marshal it
+	Key  domain.APIKey `json:"key"`
+}
+
+// keyRepo keeps api keys in memory, keys are only added so log is never compacted.
+// Keys created by another process are loaded from log when they are not found in memory
+type keyRepo struct {
+	mu   sync.RWMutex
+	keys map[string]domain.APIKey
+	log  *wal.Log
+}
+
+func openKeyRepo(path string) (*keyRepo, error) {
+	log, err := wal.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &keyRepo{
+		keys: make(map[string]domain.APIKey),
+		log:  log,
+	}
+
+	if err := log.Replay(r.load); err != nil {
+		log.Close() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// load api key record read from log
+func (r *keyRepo) load(data []byte) error {
+	var rec keyRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return fmt.Errorf("failed to decode api key: %w", err)
+	}
+
+	rec.Key.Hash = rec.Hash
+	r.keys[rec.Hash] = rec.Key
+
+	return nil
+}
+
+func (r *keyRepo) close() error {
+	return r.log.Close()
+}
+
+// Create add new api key
+func (r *keyRepo) Create(ctx context.Context, key domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[key.Hash]; ok {
+		return domain.ErrAlreadyExists
+	}
+
+	if err := r.log.Append(keyRecord{Hash: key.Hash, Key: key}); err != nil {
+		return err
+	}
+
+	r.keys[key.Hash] = key
+
+	return nil
+}
+
+// Find api key by hash of secret, missing key is looked up in records appended by other processes
+func (r *keyRepo) Find(ctx context.Context, hash string) (domain.APIKey, error) {
+	r.mu.RLock()
+	key, ok := r.keys[hash]
+	r.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.log.Follow(r.load); err != nil {
+		return domain.APIKey{}, err
+	}
+
+	key, ok = r.keys[hash]
+	if !ok {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+
+	return key, nil
+}
@@ -0,0 +1,246 @@
+package filedb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	memdb "github.com/shalimski/shortener/internal/adapters/repository/mem"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/wal"
+)
+
+const opPut = "put"
+
+var _ ports.Repository = (*urlRepo)(nil)
+
+// urlRecord is a change of url written to log
+type urlRecord struct {
+	Op             string     `json:"op"`
+	URL            domain.URL `json:"url"`
+	IdempotencyKey string     `json:"idempotency_key,omitempty"` // url does not marshal it
+	PasswordHash   string     `json:"password_hash,omitempty"`   // url does not marshal it
+}
+
+func putRecord(url domain.URL) urlRecord {
+	return urlRecord{Op: opPut, URL: url, IdempotencyKey: url.IdempotencyKey, PasswordHash: url.PasswordHash}
+}
+
+// urlRepo keeps urls in memdb, writes are serialized so log has the same order of changes
+type urlRepo struct {
+	mu  sync.Mutex
+	mem *memdb.DB
+	log *wal.Log
+}
+
+// openURLRepo replays log to memory and compacts it, expired urls are dropped
+func openURLRepo(path string) (*urlRepo, error) {
+	log, err := wal.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make(map[string]domain.URL)
+
+	err = log.Replay(func(data []byte) error {
+		var rec urlRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return fmt.Errorf("failed to decode url record: %w", err)
+		}
+
+		switch rec.Op {
+		case opPut:
+			rec.URL.IdempotencyKey = rec.IdempotencyKey
+			rec.URL.PasswordHash = rec.PasswordHash
+			urls[rec.URL.ShortURL] = rec.URL
+		default:
+			return fmt.Errorf("unknown url record operation %q", rec.Op)
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Close() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	r := &urlRepo{
+		mem: memdb.NewDB(),
+		log: log,
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	records := make([]any, 0, len(urls))
+
+	for _, url := range urls {
+		if url.IsExpired(now) {
+			continue
+		}
+
+		if err := r.mem.Create(ctx, url); err != nil {
+			log.Close() //nolint:errcheck // already failed
+
+			return nil, fmt.Errorf("failed to load url %s: %w", url.ShortURL, err)
+		}
+
+		records = append(records, putRecord(url))
+	}
+
+	if err := log.Rewrite(records); err != nil {
+		log.Close() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// created sets creation time of url, so time written to log is the same after replay
+func created(url domain.URL, now time.Time) domain.URL {
+	if url.CreatedAt.IsZero() {
+		url.CreatedAt = now.UTC()
+	}
+
+	if url.UpdatedAt.IsZero() {
+		url.UpdatedAt = url.CreatedAt
+	}
+
+	return url
+}
+
+func (r *urlRepo) close() error {
+	return r.log.Close()
+}
+
+// Create url in memory and log, url is removed from memory if log write fails
+func (r *urlRepo) Create(ctx context.Context, url domain.URL) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url = created(url, time.Now())
+
+	if err := r.mem.Create(ctx, url); err != nil {
+		return err
+	}
+
+	if err := r.log.Append(putRecord(url)); err != nil {
+		r.mem.Remove(url.ShortURL)
+
+		return err
+	}
+
+	return nil
+}
+
+// CreateMany writes all created urls to log at once
+func (r *urlRepo) CreateMany(ctx context.Context, urls []domain.URL) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	stamped := make([]domain.URL, len(urls))
+
+	for i, url := range urls {
+		stamped[i] = created(url, now)
+	}
+
+	urls = stamped
+	errs := r.mem.CreateMany(ctx, urls)
+
+	records := make([]any, 0, len(urls))
+
+	for i, url := range urls {
+		if errs[i] == nil {
+			records = append(records, putRecord(url))
+		}
+	}
+
+	if len(records) == 0 {
+		return errs
+	}
+
+	if err := r.log.Append(records...); err != nil {
+		for i, url := range urls {
+			if errs[i] == nil {
+				r.mem.Remove(url.ShortURL)
+				errs[i] = err
+			}
+		}
+	}
+
+	return errs
+}
+
+func (r *urlRepo) Find(ctx context.Context, shortURL string) (domain.URL, error) {
+	return r.mem.Find(ctx, shortURL)
+}
+
+func (r *urlRepo) FindByLongURL(ctx context.Context, owner, longURL string) (domain.URL, error) {
+	return r.mem.FindByLongURL(ctx, owner, longURL)
+}
+
+func (r *urlRepo) FindByIdempotencyKey(ctx context.Context, owner, key string) (domain.URL, error) {
+	return r.mem.FindByIdempotencyKey(ctx, owner, key)
+}
+
+func (r *urlRepo) EachShortURL(ctx context.Context, fn func(shortURL string)) error {
+	return r.mem.EachShortURL(ctx, fn)
+}
+
+func (r *urlRepo) List(ctx context.Context, query domain.ListQuery) ([]domain.URL, error) {
+	return r.mem.List(ctx, query)
+}
+
+// Update url in memory and log, previous url is restored if log write fails
+func (r *urlRepo) Update(ctx context.Context, url domain.URL) error {
+	return r.change(ctx, url.ShortURL, func() error {
+		return r.mem.Update(ctx, url)
+	})
+}
+
+// Delete marks url as deleted in memory and log, tombstone is written as a new version of url
+func (r *urlRepo) Delete(ctx context.Context, shortURL, deletedBy string) error {
+	return r.change(ctx, shortURL, func() error {
+		return r.mem.Delete(ctx, shortURL, deletedBy)
+	})
+}
+
+// Restore removes tombstone of url in memory and log
+func (r *urlRepo) Restore(ctx context.Context, shortURL string) error {
+	return r.change(ctx, shortURL, func() error {
+		return r.mem.Restore(ctx, shortURL)
+	})
+}
+
+// change applies fn to url in memory and writes changed url to log, previous url is put back if log write fails
+func (r *urlRepo) change(ctx context.Context, shortURL string, fn func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev, err := r.mem.Find(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	changed, err := r.mem.Find(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if err := r.log.Append(putRecord(changed)); err != nil {
+		r.mem.Put(prev)
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package keyrepo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collection of api keys
+const Collection = "apikeys"
+
+var _ ports.APIKeyRepository = (*keyRepo)(nil)
+
+// repository to save api keys
+type keyRepo struct {
+	collection *mongo.Collection
+}
+
+// NewKeyRepo create instance of keyRepo, indexes of apikeys collection are created by migrations
+func NewKeyRepo(db *mongo.Database) ports.APIKeyRepository {
+	return &keyRepo{
+		collection: db.Collection(Collection),
+	}
+}
+
+// Create add new api key to DB
+func (r *keyRepo) Create(ctx context.Context, key domain.APIKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrAlreadyExists
+	}
+
+	return err
+}
+
+// Find api key by hash of secret
+func (r *keyRepo) Find(ctx context.Context, hash string) (domain.APIKey, error) {
+	var key domain.APIKey
+
+	if err := r.collection.FindOne(ctx, bson.M{"hash": hash}).Decode(&key); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return domain.APIKey{}, domain.ErrNotFound
+		}
+
+		return domain.APIKey{}, err
+	}
+
+	return key, nil
+}
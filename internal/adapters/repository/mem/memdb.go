@@ -2,51 +2,212 @@ package memdb
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/shalimski/shortener/internal/domain"
 	"github.com/shalimski/shortener/internal/ports"
 )
 
-// basic realization for storage
-type memdb struct {
+// DB is basic realization for storage
+type DB struct {
 	mu sync.RWMutex
-	db map[string]string
+	db map[string]domain.URL
 }
 
 func New() ports.Repository {
-	return &memdb{db: make(map[string]string)}
+	return NewDB()
 }
 
-func (m *memdb) Create(ctx context.Context, url domain.URL) error {
+// NewDB create instance of storage for embedding into other repositories
+func NewDB() *DB {
+	return &DB{db: make(map[string]domain.URL)}
+}
+
+func (m *DB) Create(ctx context.Context, url domain.URL) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.db[url.ShortURL] = url.LongURL
+
+	if _, ok := m.db[url.ShortURL]; ok {
+		return domain.ErrAlreadyExists
+	}
+
+	if url.CreatedAt.IsZero() {
+		url.CreatedAt = time.Now().UTC()
+	}
+
+	if url.UpdatedAt.IsZero() {
+		url.UpdatedAt = url.CreatedAt
+	}
+
+	if url.IdempotencyKey != "" {
+		if _, err := m.findByIdempotencyKey(url.Owner, url.IdempotencyKey); err == nil {
+			return domain.ErrAlreadyExists
+		}
+	}
+
+	m.db[url.ShortURL] = url
 
 	return nil
 }
 
-func (m *memdb) Find(ctx context.Context, shortURL string) (domain.URL, error) {
+func (m *DB) CreateMany(ctx context.Context, urls []domain.URL) []error {
+	errs := make([]error, len(urls))
+
+	for i, url := range urls {
+		errs[i] = m.Create(ctx, url)
+	}
+
+	return errs
+}
+
+func (m *DB) Find(ctx context.Context, shortURL string) (domain.URL, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	longURL, ok := m.db[shortURL]
+	u, ok := m.db[shortURL]
 
 	if !ok {
 		return domain.URL{}, domain.ErrNotFound
 	}
 
-	u := domain.URL{
-		ShortURL: shortURL,
-		LongURL:  longURL,
+	return u, nil
+}
+
+func (m *DB) FindByLongURL(ctx context.Context, owner, longURL string) (domain.URL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+
+	for _, u := range m.db {
+		if u.LongURL == longURL && u.Owner == owner && !u.IsExpired(now) && !u.IsDeleted() {
+			return u, nil
+		}
+	}
+
+	return domain.URL{}, domain.ErrNotFound
+}
+
+func (m *DB) FindByIdempotencyKey(ctx context.Context, owner, key string) (domain.URL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.findByIdempotencyKey(owner, key)
+}
+
+func (m *DB) findByIdempotencyKey(owner, key string) (domain.URL, error) {
+	for _, u := range m.db {
+		if u.Owner == owner && u.IdempotencyKey == key {
+			return u, nil
+		}
 	}
 
-	return u, nil
+	return domain.URL{}, domain.ErrNotFound
 }
 
-func (m *memdb) Delete(ctx context.Context, shortURL string) error {
+func (m *DB) Update(ctx context.Context, url domain.URL) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+
+	u, ok := m.db[url.ShortURL]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	u.LongURL = url.LongURL
+	u.UpdatedAt = time.Now().UTC()
+	m.db[url.ShortURL] = u
+
+	return nil
+}
+
+// Delete marks url as deleted by owner of api key, tombstone is kept to be restored
+func (m *DB) Delete(ctx context.Context, shortURL, deletedBy string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.db[shortURL]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	u.DeletedAt = &now
+	u.DeletedBy = deletedBy
+	u.UpdatedAt = now
+	m.db[shortURL] = u
+
+	return nil
+}
+
+// Restore removes tombstone of deleted url
+func (m *DB) Restore(ctx context.Context, shortURL string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.db[shortURL]
+	if !ok {
+		return domain.ErrNotFound
+	}
+
+	u.DeletedAt = nil
+	u.DeletedBy = ""
+	u.UpdatedAt = time.Now().UTC()
+	m.db[shortURL] = u
+
+	return nil
+}
+
+// Put adds or replaces url as is
+func (m *DB) Put(url domain.URL) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.db[url.ShortURL] = url
+}
+
+// Remove url completely
+func (m *DB) Remove(shortURL string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	delete(m.db, shortURL)
+}
+
+func (m *DB) EachShortURL(ctx context.Context, fn func(shortURL string)) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for shortURL := range m.db {
+		fn(shortURL)
+	}
 
 	return nil
 }
+
+// List filters and sorts all urls, so it is linear in number of stored urls
+func (m *DB) List(ctx context.Context, query domain.ListQuery) ([]domain.URL, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	urls := make([]domain.URL, 0)
+
+	for _, u := range m.db {
+		if !query.Filter.Match(u) || (query.After != nil && !query.After.Precedes(u)) {
+			continue
+		}
+
+		urls = append(urls, u)
+	}
+
+	sort.Slice(urls, func(i, j int) bool {
+		return query.Sort.Less(urls[i], urls[j])
+	})
+
+	if query.Limit > 0 && len(urls) > query.Limit {
+		urls = urls[:query.Limit]
+	}
+
+	return urls, nil
+}
@@ -0,0 +1,58 @@
+package memdb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	memdb "github.com/shalimski/shortener/internal/adapters/repository/mem"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	repo := memdb.New()
+	created := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	urls := []domain.URL{
+		{ShortURL: "a", LongURL: "https://github.com/a", Owner: "dev", CreatedAt: created, Tags: []string{"code"}},
+		{ShortURL: "b", LongURL: "https://GitHub.com:443/b", Owner: "dev", CreatedAt: created.Add(time.Hour)},
+		{ShortURL: "c", LongURL: "https://example.com/github.com", Owner: "dev", CreatedAt: created},
+		{ShortURL: "d", LongURL: "https://github.com/d", Owner: "marketing", CreatedAt: created.Add(2 * time.Hour)},
+	}
+
+	for _, url := range urls {
+		require.NoError(t, repo.Create(ctx, url))
+	}
+
+	shortURLs := func(query domain.ListQuery) []string {
+		listed, err := repo.List(ctx, query)
+		require.NoError(t, err)
+
+		res := make([]string, 0, len(listed))
+		for _, url := range listed {
+			res = append(res, url.ShortURL)
+		}
+
+		return res
+	}
+
+	assert.Equal(t, []string{"d", "b", "c", "a"}, shortURLs(domain.ListQuery{Sort: domain.SortCreatedDesc}))
+	assert.Equal(t, []string{"a", "c", "b", "d"}, shortURLs(domain.ListQuery{Sort: domain.SortCreatedAsc}))
+	assert.Equal(t, []string{"d", "c", "b", "a"}, shortURLs(domain.ListQuery{Sort: domain.SortShortURLDesc}))
+
+	filter := domain.ListFilter{Owner: "dev", Domain: "github.com"}
+	assert.Equal(t, []string{"a", "b"}, shortURLs(domain.ListQuery{Filter: filter, Sort: domain.SortShortURLAsc}))
+
+	filter = domain.ListFilter{CreatedAfter: created.Add(time.Hour), CreatedBefore: created.Add(2 * time.Hour)}
+	assert.Equal(t, []string{"b"}, shortURLs(domain.ListQuery{Filter: filter, Sort: domain.SortCreatedAsc}))
+
+	filter = domain.ListFilter{Tag: "code"}
+	assert.Equal(t, []string{"a"}, shortURLs(domain.ListQuery{Filter: filter, Sort: domain.SortCreatedAsc}))
+
+	// pages continue after cursor
+	cursor := domain.NewListCursor(domain.SortCreatedAsc, urls[0])
+	assert.Equal(t, []string{"c", "b"}, shortURLs(domain.ListQuery{Sort: domain.SortCreatedAsc, After: &cursor, Limit: 2}))
+}
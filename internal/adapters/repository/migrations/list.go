@@ -0,0 +1,134 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/shalimski/shortener/internal/adapters/repository/auditrepo"
+	"github.com/shalimski/shortener/internal/adapters/repository/clickrepo"
+	"github.com/shalimski/shortener/internal/adapters/repository/keyrepo"
+	"github.com/shalimski/shortener/internal/adapters/repository/urlrepo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// all migrations, versions of released migrations must not change, new migrations are added to the end
+func all() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create indexes of links",
+			Up:          createLinkIndexes,
+		},
+		{
+			Version:     2,
+			Description: "create unique indexes of click counters and index of top referrers",
+			Up:          createClickIndexes,
+		},
+		{
+			Version:     3,
+			Description: "create unique index of api keys by hash",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection(keyrepo.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "hash", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				})
+
+				return err
+			},
+		},
+		{
+			Version:     5,
+			Description: "set timestamps and schema version of links written before explicit mapping",
+			Up:          versionLinks,
+		},
+		{
+			Version:     6,
+			Description: "create indexes of links for listing",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection(urlrepo.Collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+					{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "createdat", Value: 1}, {Key: "shorturl", Value: 1}}},
+					{Keys: bson.D{{Key: "createdat", Value: 1}, {Key: "shorturl", Value: 1}}},
+					{Keys: bson.D{{Key: "tags", Value: 1}}},
+				})
+
+				return err
+			},
+		},
+		{
+			Version:     7,
+			Description: "create index of audit events by short url and time",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				_, err := db.Collection(auditrepo.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys: bson.D{{Key: "shorturl", Value: 1}, {Key: "at", Value: 1}},
+				})
+
+				return err
+			},
+		},
+	}
+}
+
+// createLinkIndexes creates unique index by shorturl, so generated links and aliases never overlap,
+// TTL index by expiresat, so expired links are removed automatically,
+// index by longurl for deduplication and unique index by owner idempotencykey for idempotent requests
+func createLinkIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(urlrepo.Collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "shorturl", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expiresat", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys: bson.D{{Key: "longurl", Value: 1}, {Key: "owner", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "owner", Value: 1}, {Key: "idempotencykey", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"idempotencykey": bson.M{"$gt": ""}}),
+		},
+	})
+
+	return err
+}
+
+// createClickIndexes creates unique indexes of counters by short url and day or referrer,
+// so concurrent upserts of nodes never create the same counter twice
+func createClickIndexes(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(clickrepo.DailyCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "shorturl", Value: 1}, {Key: "day", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(clickrepo.ReferrerCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "shorturl", Value: 1}, {Key: "referrer", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "shorturl", Value: 1}, {Key: "clicks", Value: -1}, {Key: "referrer", Value: 1}},
+		},
+	})
+
+	return err
+}
+
+// versionLinks sets schema version 1 of links without version, creation time is taken from object id
+func versionLinks(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection(urlrepo.Collection).UpdateMany(ctx,
+		bson.M{"schemaversion": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.D{
+			{Key: "createdat", Value: bson.M{"$toDate": "$_id"}},
+			{Key: "updatedat", Value: bson.M{"$toDate": "$_id"}},
+			{Key: "schemaversion", Value: 1},
+		}}}},
+	)
+
+	return err
+}
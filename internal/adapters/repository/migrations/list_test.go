@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersions(t *testing.T) {
+	prev := 0
+
+	for _, m := range all() {
+		assert.Greater(t, m.Version, prev, "versions are unique and ascending")
+		assert.NotEmpty(t, m.Description)
+		assert.NotNil(t, m.Up)
+
+		prev = m.Version
+	}
+}
@@ -0,0 +1,111 @@
+// Package migrations changes schema of MongoDB collections on startup.
+// Applied versions are recorded in migrations collection, so every migration runs once
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/shalimski/shortener/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+const (
+	collection = "migrations"
+	lockKey    = "/locks/migrations"
+)
+
+// Migration changes schema of database, it must be safe to rerun,
+// because node can fail after migration is applied but before it is recorded
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+// Locker guards migrations, so only one node runs them
+type Locker interface {
+	Lock(ctx context.Context, key string) (unlock func() error, err error)
+}
+
+// record of applied migration
+type record struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"appliedat"`
+}
+
+// Run applies pending migrations in order of versions while lock is held,
+// nil locker is allowed for single node deployments
+func Run(ctx context.Context, db *mongo.Database, locker Locker, log *logger.Logger) error {
+	return apply(ctx, db, locker, log, all())
+}
+
+func apply(ctx context.Context, db *mongo.Database, locker Locker, log *logger.Logger, migrations []Migration) error {
+	if locker != nil {
+		unlock, err := locker.Lock(ctx, lockKey)
+		if err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+
+		defer func() {
+			if err := unlock(); err != nil {
+				log.Error(ctx, "failed to unlock migrations", zap.Error(err))
+			}
+		}()
+	}
+
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+
+		log.Info(ctx, "applying migration", zap.Int("version", m.Version), zap.String("description", m.Description))
+
+		if err := m.Up(ctx, db); err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", m.Version, err)
+		}
+
+		rec := record{Version: m.Version, Description: m.Description, AppliedAt: time.Now().UTC()}
+
+		_, err := db.Collection(collection).InsertOne(ctx, rec)
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+		}
+	}
+
+	return nil
+}
+
+// appliedVersions reads versions recorded in migrations collection
+func appliedVersions(ctx context.Context, db *mongo.Database) (map[int]bool, error) {
+	cur, err := db.Collection(collection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var records []record
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	applied := make(map[int]bool, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = true
+	}
+
+	return applied, nil
+}
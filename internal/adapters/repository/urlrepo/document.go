@@ -0,0 +1,89 @@
+package urlrepo
+
+import (
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+)
+
+// schemaVersion of link documents, it is increased together with migration of stored documents
+const schemaVersion = 1
+
+// names of fields of link document used in queries and indexes
+const (
+	fieldShortURL       = "shorturl"
+	fieldLongURL        = "longurl"
+	fieldExpiresAt      = "expiresat"
+	fieldIdempotencyKey = "idempotencykey"
+	fieldOwner          = "owner"
+	fieldTags           = "tags"
+	fieldCreatedAt      = "createdat"
+	fieldUpdatedAt      = "updatedat"
+	fieldDeletedAt      = "deletedat"
+	fieldDeletedBy      = "deletedby"
+)
+
+// linkDocument is stored shape of url, field names match documents written before explicit mapping
+type linkDocument struct {
+	ShortURL       string     `bson:"shorturl"`
+	LongURL        string     `bson:"longurl"`
+	ExpiresAt      *time.Time `bson:"expiresat"` // null means url never expires
+	IdempotencyKey string     `bson:"idempotencykey"`
+	Owner          string     `bson:"owner"`
+	RedirectCode   int        `bson:"redirectcode"`
+	Title          string     `bson:"title,omitempty"`
+	Tags           []string   `bson:"tags,omitempty"`
+	Notes          string     `bson:"notes,omitempty"`
+	CreatedAt      time.Time  `bson:"createdat"`
+	UpdatedAt      time.Time  `bson:"updatedat"`
+	DeletedAt      *time.Time `bson:"deletedat,omitempty"` // missing means url is not deleted
+	DeletedBy      string     `bson:"deletedby,omitempty"`
+	PasswordHash   string     `bson:"passwordhash,omitempty"` // missing means url is public
+	SchemaVersion  int        `bson:"schemaversion"`
+}
+
+// toDocument maps url to document created at now, unless url has own creation time
+func toDocument(url domain.URL, now time.Time) linkDocument {
+	now = now.UTC()
+
+	createdAt := url.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+
+	return linkDocument{
+		ShortURL:       url.ShortURL,
+		LongURL:        url.LongURL,
+		ExpiresAt:      url.ExpiresAt,
+		IdempotencyKey: url.IdempotencyKey,
+		Owner:          url.Owner,
+		RedirectCode:   url.RedirectCode,
+		Title:          url.Title,
+		Tags:           url.Tags,
+		Notes:          url.Notes,
+		PasswordHash:   url.PasswordHash,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+		SchemaVersion:  schemaVersion,
+	}
+}
+
+// toDomain maps stored document to url
+func (d linkDocument) toDomain() domain.URL {
+	return domain.URL{
+		ShortURL:       d.ShortURL,
+		LongURL:        d.LongURL,
+		ExpiresAt:      d.ExpiresAt,
+		IdempotencyKey: d.IdempotencyKey,
+		Owner:          d.Owner,
+		RedirectCode:   d.RedirectCode,
+		Title:          d.Title,
+		Tags:           d.Tags,
+		Notes:          d.Notes,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+		DeletedAt:      d.DeletedAt,
+		DeletedBy:      d.DeletedBy,
+		PasswordHash:   d.PasswordHash,
+	}
+}
@@ -0,0 +1,62 @@
+package urlrepo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDocumentMapping(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	url := domain.URL{
+		ShortURL:       "abcd",
+		LongURL:        "http://github.com",
+		ExpiresAt:      &expiresAt,
+		IdempotencyKey: "key",
+		Owner:          "marketing",
+		RedirectCode:   302,
+		Title:          "Repository",
+		Tags:           []string{"promo"},
+		Notes:          "shared in newsletter",
+		PasswordHash:   "hash",
+	}
+
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	doc := toDocument(url, now)
+
+	assert.Equal(t, now, doc.CreatedAt)
+	assert.Equal(t, now, doc.UpdatedAt)
+	assert.Equal(t, schemaVersion, doc.SchemaVersion)
+
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+
+	var decoded linkDocument
+	require.NoError(t, bson.Unmarshal(data, &decoded))
+
+	url.CreatedAt = now
+	url.UpdatedAt = now
+	assert.Equal(t, url, decoded.toDomain())
+}
+
+func TestDocumentFields(t *testing.T) {
+	data, err := bson.Marshal(toDocument(domain.URL{ShortURL: "abcd", Title: "title", Tags: []string{"promo"}, Notes: "notes"}, time.Now()))
+	require.NoError(t, err)
+
+	var fields bson.M
+	require.NoError(t, bson.Unmarshal(data, &fields))
+
+	// stored shape must not change without migration
+	for _, name := range []string{
+		fieldShortURL, fieldLongURL, fieldExpiresAt, fieldIdempotencyKey, fieldOwner,
+		"redirectcode", "title", fieldTags, "notes", fieldCreatedAt, fieldUpdatedAt, "schemaversion",
+	} {
+		assert.Contains(t, fields, name)
+	}
+
+	assert.Len(t, fields, 12)
+}
@@ -3,14 +3,19 @@ package urlrepo
 import (
 	"context"
 	"errors"
+	"regexp"
+	"time"
 
 	"github.com/shalimski/shortener/internal/domain"
 	"github.com/shalimski/shortener/internal/ports"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const urlCollection = "links"
+// Collection of links
+const Collection = "links"
 
 var _ ports.Repository = (*urlRepo)(nil)
 
@@ -19,10 +24,10 @@ type urlRepo struct {
 	collection *mongo.Collection
 }
 
-// NewURLRepo create instance of urlRepo
+// NewURLRepo create instance of urlRepo, indexes of links collection are created by migrations
 func NewURLRepo(db *mongo.Database) ports.Repository {
 	return &urlRepo{
-		collection: db.Collection(urlCollection),
+		collection: db.Collection(Collection),
 	}
 }
 
@@ -34,22 +39,95 @@ func (r *urlRepo) Create(ctx context.Context, url domain.URL) error {
 	default:
 	}
 
-	_, err := r.collection.InsertOne(ctx, url)
+	_, err := r.collection.InsertOne(ctx, toDocument(url, time.Now()))
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrAlreadyExists
+	}
 
 	return err
 }
 
+// CreateMany add batch of values to DB, returns error of every value, nil for inserted ones
+func (r *urlRepo) CreateMany(ctx context.Context, urls []domain.URL) []error {
+	errs := make([]error, len(urls))
+
+	select {
+	case <-ctx.Done():
+		return fillErrors(errs, ctx.Err())
+	default:
+	}
+
+	now := time.Now()
+
+	docs := make([]any, 0, len(urls))
+	for _, url := range urls {
+		docs = append(docs, toDocument(url, now))
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return errs
+	}
+
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) || len(bwe.WriteErrors) == 0 {
+		return fillErrors(errs, err)
+	}
+
+	for _, we := range bwe.WriteErrors {
+		if mongo.IsDuplicateKeyError(we) {
+			errs[we.Index] = domain.ErrAlreadyExists
+
+			continue
+		}
+
+		errs[we.Index] = we
+	}
+
+	return errs
+}
+
+func fillErrors(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
+}
+
 // Find first value by shortURL
 func (r *urlRepo) Find(ctx context.Context, shortURL string) (domain.URL, error) {
+	return r.findOne(ctx, bson.M{fieldShortURL: shortURL})
+}
+
+// FindByLongURL first not expired and not deleted value of owner by longURL
+func (r *urlRepo) FindByLongURL(ctx context.Context, owner, longURL string) (domain.URL, error) {
+	return r.findOne(ctx, bson.M{
+		fieldLongURL:   longURL,
+		fieldOwner:     owner,
+		fieldDeletedAt: nil,
+		"$or": bson.A{
+			bson.M{fieldExpiresAt: nil},
+			bson.M{fieldExpiresAt: bson.M{"$gt": time.Now()}},
+		},
+	})
+}
+
+// FindByIdempotencyKey value created by owner request with idempotency key
+func (r *urlRepo) FindByIdempotencyKey(ctx context.Context, owner, key string) (domain.URL, error) {
+	return r.findOne(ctx, bson.M{fieldOwner: owner, fieldIdempotencyKey: key})
+}
+
+func (r *urlRepo) findOne(ctx context.Context, filter bson.M) (domain.URL, error) {
 	select {
 	case <-ctx.Done():
 		return domain.URL{}, ctx.Err()
 	default:
 	}
 
-	var url domain.URL
+	var doc linkDocument
 
-	if err := r.collection.FindOne(ctx, bson.M{"shorturl": shortURL}).Decode(&url); err != nil {
+	if err := r.collection.FindOne(ctx, filter).Decode(&doc); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return domain.URL{}, domain.ErrNotFound
 		}
@@ -57,25 +135,159 @@ func (r *urlRepo) Find(ctx context.Context, shortURL string) (domain.URL, error)
 		return domain.URL{}, err
 	}
 
-	return url, nil
+	return doc.toDomain(), nil
+}
+
+// Update long url of value by short url
+func (r *urlRepo) Update(ctx context.Context, url domain.URL) error {
+	return r.updateOne(ctx, url.ShortURL, bson.M{"$set": bson.M{fieldLongURL: url.LongURL, fieldUpdatedAt: time.Now().UTC()}})
 }
 
-// Delete value by short url
-func (r *urlRepo) Delete(ctx context.Context, shortURL string) error {
+// Delete marks value as deleted, tombstone is kept to be restored
+func (r *urlRepo) Delete(ctx context.Context, shortURL, deletedBy string) error {
+	now := time.Now().UTC()
+
+	return r.updateOne(ctx, shortURL, bson.M{"$set": bson.M{
+		fieldDeletedAt: now,
+		fieldDeletedBy: deletedBy,
+		fieldUpdatedAt: now,
+	}})
+}
+
+// Restore removes tombstone of value
+func (r *urlRepo) Restore(ctx context.Context, shortURL string) error {
+	return r.updateOne(ctx, shortURL, bson.M{
+		"$unset": bson.M{fieldDeletedAt: "", fieldDeletedBy: ""},
+		"$set":   bson.M{fieldUpdatedAt: time.Now().UTC()},
+	})
+}
+
+func (r *urlRepo) updateOne(ctx context.Context, shortURL string, update bson.M) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	dresult, err := r.collection.DeleteOne(ctx, bson.M{"shorturl": shortURL})
+	uresult, err := r.collection.UpdateOne(ctx, bson.M{fieldShortURL: shortURL}, update)
 	if err != nil {
 		return err
 	}
 
-	if dresult.DeletedCount != 1 {
+	if uresult.MatchedCount != 1 {
 		return domain.ErrNotFound
 	}
 
-	return err
+	return nil
+}
+
+// EachShortURL calls fn for short url of every stored url
+func (r *urlRepo) EachShortURL(ctx context.Context, fn func(shortURL string)) error {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{fieldShortURL: 1, "_id": 0}))
+	if err != nil {
+		return err
+	}
+
+	defer cursor.Close(ctx) //nolint:errcheck // simple
+
+	for cursor.Next(ctx) {
+		var doc linkDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+
+		fn(doc.ShortURL)
+	}
+
+	return cursor.Err()
+}
+
+// List page of values selected by filter in order of sort, page starts after cursor
+func (r *urlRepo) List(ctx context.Context, query domain.ListQuery) ([]domain.URL, error) {
+	filter := bson.M{"$and": listConditions(query)}
+
+	order := 1
+	if query.Sort.Desc() {
+		order = -1
+	}
+
+	sort := bson.D{{Key: fieldShortURL, Value: order}}
+	if query.Sort.ByCreated() {
+		sort = bson.D{{Key: fieldCreatedAt, Value: order}, {Key: fieldShortURL, Value: order}}
+	}
+
+	opts := options.Find().SetSort(sort)
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []linkDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	urls := make([]domain.URL, 0, len(docs))
+	for _, doc := range docs {
+		urls = append(urls, doc.toDomain())
+	}
+
+	return urls, nil
+}
+
+// listConditions converts filter and cursor of query to conditions of find
+func listConditions(query domain.ListQuery) bson.A {
+	f := query.Filter
+
+	deleted := bson.M{fieldDeletedAt: nil}
+	if f.Deleted {
+		deleted = bson.M{fieldDeletedAt: bson.M{"$ne": nil}}
+	}
+
+	conditions := bson.A{deleted}
+
+	if f.Owner != "" {
+		conditions = append(conditions, bson.M{fieldOwner: f.Owner})
+	}
+
+	if !f.CreatedAfter.IsZero() {
+		conditions = append(conditions, bson.M{fieldCreatedAt: bson.M{"$gte": f.CreatedAfter}})
+	}
+
+	if !f.CreatedBefore.IsZero() {
+		conditions = append(conditions, bson.M{fieldCreatedAt: bson.M{"$lt": f.CreatedBefore}})
+	}
+
+	if f.Domain != "" {
+		// host follows scheme and optional user info, port or path may follow it
+		pattern := "^[a-z][a-z0-9+.-]*://([^/?#@]*@)?" + regexp.QuoteMeta(f.Domain) + "(:[0-9]*)?([/?#]|$)"
+		conditions = append(conditions, bson.M{fieldLongURL: primitive.Regex{Pattern: pattern, Options: "i"}})
+	}
+
+	if f.Tag != "" {
+		conditions = append(conditions, bson.M{fieldTags: f.Tag})
+	}
+
+	if c := query.After; c != nil {
+		op := "$gt"
+		if query.Sort.Desc() {
+			op = "$lt"
+		}
+
+		after := bson.M{fieldShortURL: bson.M{op: c.ShortURL}}
+		if query.Sort.ByCreated() {
+			after = bson.M{"$or": bson.A{
+				bson.M{fieldCreatedAt: bson.M{op: c.CreatedAt}},
+				bson.M{fieldCreatedAt: c.CreatedAt, fieldShortURL: bson.M{op: c.ShortURL}},
+			}}
+		}
+
+		conditions = append(conditions, after)
+	}
+
+	return conditions
 }
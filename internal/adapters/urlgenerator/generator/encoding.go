@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Base62 is the default alphabet of short urls
+const Base62 = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// unreserved are characters allowed in URL path without escaping
+const unreserved = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-._~"
+
+var (
+	ErrInvalidAlphabet = errors.New("alphabet must contain at least 2 unique unreserved URL characters")
+	ErrInvalidLength   = errors.New("invalid short url length limits")
+	ErrTooLong         = errors.New("short url exceeds max length")
+	ErrInvalidShortURL = errors.New("short url has characters out of alphabet")
+)
+
+// Encoding converts counter values to short urls made of alphabet, first character of alphabet is zero digit
+type Encoding struct {
+	alphabet  string
+	index     [256]int // position of character in alphabet, -1 for characters out of alphabet
+	minLength int      // shorter values are padded by zero digit
+	maxLength int      // zero means length is not limited
+}
+
+// NewEncoding create encoding of alphabet, short urls are padded to minLength and never exceed maxLength
+func NewEncoding(alphabet string, minLength, maxLength int) (*Encoding, error) {
+	if len(alphabet) < 2 {
+		return nil, ErrInvalidAlphabet
+	}
+
+	if minLength < 0 || maxLength < 0 || (maxLength != 0 && minLength > maxLength) {
+		return nil, fmt.Errorf("%w: min %d, max %d", ErrInvalidLength, minLength, maxLength)
+	}
+
+	e := &Encoding{
+		alphabet:  alphabet,
+		minLength: minLength,
+		maxLength: maxLength,
+	}
+
+	for i := range e.index {
+		e.index[i] = -1
+	}
+
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if e.index[c] != -1 || !strings.ContainsRune(unreserved, rune(c)) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAlphabet, c)
+		}
+
+		e.index[c] = i
+	}
+
+	return e, nil
+}
+
+// Encode returns representation of non-negative num in alphabet
+func (e *Encoding) Encode(num int) (string, error) {
+	if num < 0 {
+		return "", fmt.Errorf("negative value %d", num)
+	}
+
+	base := len(e.alphabet)
+	result := make([]byte, 0, e.minLength)
+
+	for num > 0 {
+		result = append(result, e.alphabet[num%base])
+		num /= base
+	}
+
+	for len(result) < e.minLength {
+		result = append(result, e.alphabet[0])
+	}
+
+	if e.maxLength != 0 && len(result) > e.maxLength {
+		return "", ErrTooLong
+	}
+
+	// digits were appended from the least significant one
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return string(result), nil
+}
+
+// Decode returns value of short url, padding is ignored
+func (e *Encoding) Decode(shortURL string) (int, error) {
+	if shortURL == "" {
+		return 0, ErrInvalidShortURL
+	}
+
+	if e.maxLength != 0 && len(shortURL) > e.maxLength {
+		return 0, ErrTooLong
+	}
+
+	base := len(e.alphabet)
+	num := 0
+
+	for i := 0; i < len(shortURL); i++ {
+		digit := e.index[shortURL[i]]
+		if digit == -1 {
+			return 0, ErrInvalidShortURL
+		}
+
+		if num > (math.MaxInt-digit)/base {
+			return 0, fmt.Errorf("short url %q overflows int", shortURL)
+		}
+
+		num = num*base + digit
+	}
+
+	return num, nil
+}
+
+// base62 is encoding of package level functions, length is not limited
+func base62() *Encoding {
+	e, _ := NewEncoding(Base62, 0, 0)
+
+	return e
+}
+
+// Encode returns base62 representation of int
+func Encode(num int) string {
+	shortURL, err := base62().Encode(num)
+	if err != nil {
+		return ""
+	}
+
+	return shortURL
+}
+
+// Decode returns int represented by base62 string
+func Decode(shortURL string) (int, error) {
+	return base62().Decode(shortURL)
+}
@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEncoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		alphabet  string
+		minLength int
+		maxLength int
+		wantErr   error
+	}{
+		{"base62", Base62, 0, 11, nil},
+		{"short alphabet", "a", 0, 0, ErrInvalidAlphabet},
+		{"duplicate char", "abca", 0, 0, ErrInvalidAlphabet},
+		{"reserved char", "ab/", 0, 0, ErrInvalidAlphabet},
+		{"min above max", Base62, 8, 7, ErrInvalidLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEncoding(tt.alphabet, tt.minLength, tt.maxLength)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	// no ambiguous 0/O/l/1/I
+	const alphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	e, err := NewEncoding(alphabet, 4, 8)
+	require.NoError(t, err)
+
+	base := len(alphabet)
+	limit := 1 // first value exceeding max length
+	for i := 0; i < 8; i++ {
+		limit *= base
+	}
+
+	for _, num := range []int{0, 1, base - 1, base, 1_000_000, limit - 1} {
+		shortURL, err := e.Encode(num)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, len(shortURL), 4, "padded to min length")
+
+		decoded, err := e.Decode(shortURL)
+		require.NoError(t, err)
+		assert.Equal(t, num, decoded)
+	}
+
+	shortURL, err := e.Encode(base + 1)
+	require.NoError(t, err)
+	assert.Equal(t, "aabb", shortURL)
+
+	_, err = e.Encode(limit)
+	assert.ErrorIs(t, err, ErrTooLong)
+
+	_, err = e.Decode("abc0")
+	assert.ErrorIs(t, err, ErrInvalidShortURL)
+
+	_, err = e.Decode("abcdefghj")
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestDecode(t *testing.T) {
+	num, err := Decode("emjc")
+	assert.NoError(t, err)
+	assert.Equal(t, 1_000_000, num)
+
+	_, err = Decode("")
+	assert.ErrorIs(t, err, ErrInvalidShortURL)
+
+	_, err = Decode("99999999999999")
+	assert.Error(t, err, "overflow")
+}
+
+func TestGeneratorEncoding(t *testing.T) {
+	e, err := NewEncoding("ab", 3, 4)
+	require.NoError(t, err)
+
+	gen, err := NewURLGenerator(&MockCounter{}, Interval(10), UseEncoding(e), LowWatermark(0))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	shortURLs, err := gen.NextN(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aab", "aba", "abb"}, shortURLs)
+
+	// 16 does not fit into 4 binary digits
+	_, err = gen.NextN(ctx, 15)
+	assert.ErrorIs(t, err, ErrTooLong)
+}
@@ -5,32 +5,97 @@ package generator
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/shalimski/shortener/internal/ports"
 )
 
 const (
-	interval = 100000
-	base     = 62
-	chars    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	defaultInterval = 100000
+
+	prefetchTimeout    = 5 * time.Second
+	prefetchRetryDelay = time.Second // pause after failed prefetch, so unavailable coordinator is not flooded
 )
 
 type urlGenerator struct {
 	mu          sync.Mutex
-	currCounter int // current value of interval, Encode(current) will be next shortURL
+	currCounter int // current value of interval, encoding.Encode(current) will be next shortURL
 	maxCounter  int // max value of interval, when will be reached, it need to request new interval
 
-	counter Counter // distributed counter
+	nextCounter  int       // prefetched value of counter for next interval, zero means it is not reserved yet
+	lowWatermark int       // remaining short urls of interval when next interval is prefetched, zero disables prefetch
+	prefetching  bool      // prefetch is in progress
+	retryAt      time.Time // failed prefetch is not retried before
+
+	counter  Counter   // distributed counter
+	interval int       // number of short urls reserved by one value of counter
+	encoding *Encoding // converts values of interval to short urls
+
+	key         string       // secret of permutation, empty key keeps short urls sequential
+	permutation *permutation // hides order of values of interval
 }
 
 type Counter interface {
 	NextCounter(context.Context) (int, error)
 }
 
-func NewURLGenerator(counter Counter) (ports.ShortURLGenerator, error) {
+type Option func(*urlGenerator)
+
+// LowWatermark sets number of remaining short urls of interval when next interval is reserved in background,
+// zero disables prefetch, by default it is a tenth of interval
+func LowWatermark(n int) Option {
+	return func(u *urlGenerator) {
+		u.lowWatermark = n
+	}
+}
+
+// Interval sets number of short urls reserved by one value of counter,
+// all nodes must use the same interval, it can not be changed after short urls were generated
+func Interval(n int) Option {
+	return func(u *urlGenerator) {
+		u.interval = n
+	}
+}
+
+// UseEncoding sets alphabet and length limits of short urls, base62 without limits is used by default
+func UseEncoding(e *Encoding) Option {
+	return func(u *urlGenerator) {
+		u.encoding = e
+	}
+}
+
+// Obfuscate makes short urls unpredictable by keyed permutation of counter values,
+// short urls stay unique while key, alphabet and max length are not changed
+func Obfuscate(key string) Option {
+	return func(u *urlGenerator) {
+		u.key = key
+	}
+}
+
+func NewURLGenerator(counter Counter, opts ...Option) (ports.ShortURLGenerator, error) {
 	u := &urlGenerator{
-		counter: counter,
+		counter:      counter,
+		interval:     defaultInterval,
+		encoding:     base62(),
+		lowWatermark: -1,
+	}
+
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	if u.interval < 1 {
+		return nil, fmt.Errorf("invalid interval %d", u.interval)
+	}
+
+	if u.lowWatermark < 0 {
+		u.lowWatermark = u.interval / 10
+	}
+
+	if u.key != "" {
+		u.permutation = newPermutation(u.key, u.encoding.capacity()-1)
 	}
 
 	ctx := context.Background()
@@ -46,45 +111,112 @@ func (u *urlGenerator) Next(ctx context.Context) (string, error) {
 	u.mu.Lock()
 	defer u.mu.Unlock()
 
+	return u.take(ctx)
+}
+
+// NextN values of short URL, all of them are reserved at once
+func (u *urlGenerator) NextN(ctx context.Context, n int) ([]string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	shortURLs := make([]string, 0, n)
+
+	for i := 0; i < n; i++ {
+		shortURL, err := u.take(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	return shortURLs, nil
+}
+
+// take next value of short URL, caller holds mutex
+func (u *urlGenerator) take(ctx context.Context) (string, error) {
 	if u.currCounter == u.maxCounter {
 		if err := u.setNextInterval(ctx); err != nil {
 			return "", err
 		}
 	}
 
-	shortURL := Encode(u.currCounter)
+	value := u.currCounter
+	if u.permutation != nil {
+		// zero is encoded as empty string, so values from one are permuted
+		if uint64(value) > u.permutation.size {
+			return "", ErrTooLong
+		}
+
+		value = int(u.permutation.Permute(uint64(value-1))) + 1
+	}
+
+	shortURL, err := u.encoding.Encode(value)
+	if err != nil {
+		return "", err
+	}
+
 	u.currCounter++
 
+	u.prefetch()
+
 	return shortURL, nil
 }
 
-// setNextInterval get next value of distributed counter and set next interval based on it
-func (u *urlGenerator) setNextInterval(ctx context.Context) error {
-	next, err := u.counter.NextCounter(ctx)
-	if err != nil {
-		return err
+// prefetch reserves next interval in background when current interval is running low, caller holds mutex
+func (u *urlGenerator) prefetch() {
+	if u.lowWatermark <= 0 || u.nextCounter != 0 || u.prefetching ||
+		u.maxCounter-u.currCounter > u.lowWatermark || time.Now().Before(u.retryAt) {
+		return
 	}
 
-	u.currCounter = 1 + interval*(next-1)
-	u.maxCounter = interval * next
+	u.prefetching = true
 
-	return nil
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), prefetchTimeout)
+		defer cancel()
+
+		next, err := u.counter.NextCounter(ctx)
+
+		u.mu.Lock()
+		defer u.mu.Unlock()
+
+		u.prefetching = false
+
+		if err != nil {
+			u.retryAt = time.Now().Add(prefetchRetryDelay)
+
+			return
+		}
+
+		u.nextCounter = next
+	}()
 }
 
-// Encode returns base62 representation of int
-func Encode(num int) string {
-	if num < 0 {
-		return ""
-	}
+// Remaining count of short URLs left in current interval
+func (u *urlGenerator) Remaining() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.maxCounter - u.currCounter
+}
 
-	result := make([]byte, 0)
+// setNextInterval takes prefetched value or gets next value of distributed counter and set next interval based on it
+func (u *urlGenerator) setNextInterval(ctx context.Context) error {
+	next := u.nextCounter
+	u.nextCounter = 0
 
-	for num > 0 {
-		curr := num % base
-		num /= base
+	if next == 0 {
+		var err error
 
-		result = append([]byte{chars[curr]}, result...)
+		next, err = u.counter.NextCounter(ctx)
+		if err != nil {
+			return err
+		}
 	}
 
-	return string(result)
+	u.currCounter = 1 + u.interval*(next-1)
+	u.maxCounter = u.interval * next
+
+	return nil
 }
@@ -5,7 +5,10 @@ package generator
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -38,15 +41,90 @@ func TestNewUrlGenerator(t *testing.T) {
 	assert.Equal(t, second, "rML8")
 }
 
+func TestNextN(t *testing.T) {
+	counter := &MockCounter{}
+	gen, err := NewURLGenerator(counter, LowWatermark(0))
+
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	shortURLs, err := gen.NextN(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c", "d"}, shortURLs)
+
+	// batch crosses the end of interval
+	shortURLs, err = gen.NextN(ctx, defaultInterval)
+	assert.NoError(t, err)
+	assert.Len(t, shortURLs, defaultInterval)
+	assert.Equal(t, 2, counter.Current())
+
+	unique := make(map[string]struct{}, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		unique[shortURL] = struct{}{}
+	}
+
+	assert.Len(t, unique, defaultInterval)
+}
+
+func TestPrefetch(t *testing.T) {
+	counter := &MockCounter{}
+	gen, err := NewURLGenerator(counter, LowWatermark(defaultInterval))
+
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = gen.Next(ctx)
+	assert.NoError(t, err)
+
+	// next interval is reserved in background
+	assert.Eventually(t, func() bool {
+		return counter.Current() == 2
+	}, time.Second, time.Millisecond)
+
+	// generator keeps working with prefetched interval while coordinator is unavailable
+	counter.Fail(errors.New("etcd is unavailable"))
+
+	shortURLs, err := gen.NextN(ctx, defaultInterval)
+	assert.NoError(t, err)
+	assert.Len(t, shortURLs, defaultInterval)
+	assert.Equal(t, Encode(defaultInterval+2), shortURLs[defaultInterval-1], "last short url is from prefetched interval")
+
+	_, err = gen.NextN(ctx, defaultInterval)
+	assert.Error(t, err)
+}
+
 type MockCounter struct {
+	mu      sync.Mutex
 	current int
+	err     error
 }
 
 func (m *MockCounter) NextCounter(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return 0, m.err
+	}
+
 	m.current = m.current + 1
 	return m.current, nil
 }
 
+func (m *MockCounter) Current() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.current
+}
+
+func (m *MockCounter) Fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.err = err
+}
+
 func TestEncode(t *testing.T) {
 	tests := []struct {
 		name string
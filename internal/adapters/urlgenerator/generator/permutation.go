@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+const (
+	feistelRounds = 8
+	maxDomainBits = 62 // domain of permutation fits int
+)
+
+// permutation is a keyed bijection of [0, size) built as Feistel network over balanced halves of bits,
+// values out of size are walked through network again until they fall in size
+type permutation struct {
+	size     uint64
+	halfBits uint
+	mask     uint64 // mask of half
+	keys     [feistelRounds][sha256.Size]byte
+}
+
+func newPermutation(key string, size uint64) *permutation {
+	domainBits := uint(bits.Len64(size - 1))
+	if domainBits%2 == 1 {
+		domainBits++
+	}
+
+	if domainBits < 2 {
+		domainBits = 2
+	}
+
+	p := &permutation{
+		size:     size,
+		halfBits: domainBits / 2,
+		mask:     1<<(domainBits/2) - 1,
+	}
+
+	for i := range p.keys {
+		p.keys[i] = sha256.Sum256(append([]byte{byte(i)}, key...))
+	}
+
+	return p
+}
+
+// round is pseudo random function of half keyed by round key
+func (p *permutation) round(i int, half uint64) uint64 {
+	var buf [sha256.Size + 8]byte
+
+	copy(buf[:], p.keys[i][:])
+	binary.BigEndian.PutUint64(buf[sha256.Size:], half)
+
+	sum := sha256.Sum256(buf[:])
+
+	return binary.BigEndian.Uint64(sum[:8]) & p.mask
+}
+
+func (p *permutation) encrypt(x uint64) uint64 {
+	left, right := x>>p.halfBits, x&p.mask
+
+	for i := 0; i < feistelRounds; i++ {
+		left, right = right, left^p.round(i, right)
+	}
+
+	return left<<p.halfBits | right
+}
+
+func (p *permutation) decrypt(y uint64) uint64 {
+	left, right := y>>p.halfBits, y&p.mask
+
+	for i := feistelRounds - 1; i >= 0; i-- {
+		left, right = right^p.round(i, left), left
+	}
+
+	return left<<p.halfBits | right
+}
+
+// Permute maps value of [0, size) to another value of [0, size)
+func (p *permutation) Permute(x uint64) uint64 {
+	x = p.encrypt(x)
+	for x >= p.size {
+		x = p.encrypt(x)
+	}
+
+	return x
+}
+
+// Invert is inverse of Permute
+func (p *permutation) Invert(y uint64) uint64 {
+	y = p.decrypt(y)
+	for y >= p.size {
+		y = p.decrypt(y)
+	}
+
+	return y
+}
+
+// capacity is number of values encoding represents within max length, limited by domain of permutation
+func (e *Encoding) capacity() uint64 {
+	limit := uint64(1) << maxDomainBits
+	if e.maxLength == 0 {
+		return limit
+	}
+
+	capacity := uint64(1)
+
+	for i := 0; i < e.maxLength; i++ {
+		if capacity > math.MaxUint64/uint64(len(e.alphabet)) {
+			return limit
+		}
+
+		capacity *= uint64(len(e.alphabet))
+		if capacity >= limit {
+			return limit
+		}
+	}
+
+	return capacity
+}
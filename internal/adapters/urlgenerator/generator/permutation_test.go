@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermutationBijective(t *testing.T) {
+	for _, size := range []uint64{1, 2, 1000, 4096, 62 * 62 * 62} {
+		p := newPermutation("secret", size)
+		seen := make(map[uint64]struct{}, size)
+
+		for x := uint64(0); x < size; x++ {
+			y := p.Permute(x)
+			require.Less(t, y, size, "value out of range")
+			require.Equal(t, x, p.Invert(y))
+
+			seen[y] = struct{}{}
+		}
+
+		assert.Len(t, seen, int(size), "every value has own image")
+	}
+}
+
+func TestPermutationKey(t *testing.T) {
+	a := newPermutation("secret", 1<<20)
+	b := newPermutation("another secret", 1<<20)
+
+	same := 0
+
+	for x := uint64(0); x < 1000; x++ {
+		if a.Permute(x) == b.Permute(x) {
+			same++
+		}
+	}
+
+	assert.Less(t, same, 10, "permutation depends on key")
+}
+
+func TestObfuscate(t *testing.T) {
+	e, err := NewEncoding(Base62, 0, 4)
+	require.NoError(t, err)
+
+	gen, err := NewURLGenerator(&MockCounter{}, Interval(1000), UseEncoding(e), Obfuscate("secret"), LowWatermark(0))
+	require.NoError(t, err)
+
+	shortURLs, err := gen.NextN(context.Background(), 5000)
+	require.NoError(t, err)
+
+	unique := make(map[string]struct{}, len(shortURLs))
+	sequential := 0
+
+	for i, shortURL := range shortURLs {
+		assert.NotEmpty(t, shortURL)
+		assert.LessOrEqual(t, len(shortURL), 4)
+
+		unique[shortURL] = struct{}{}
+
+		if i > 0 {
+			prev, _ := Decode(shortURLs[i-1])
+			curr, _ := Decode(shortURL)
+
+			if curr == prev+1 {
+				sequential++
+			}
+		}
+	}
+
+	assert.Len(t, unique, len(shortURLs))
+	assert.Less(t, sequential, 10, "short urls are not sequential")
+}
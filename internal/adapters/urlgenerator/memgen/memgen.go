@@ -19,3 +19,12 @@ func NewURLGenerator(length int) ports.ShortURLGenerator {
 func (u *urlGenerator) Next(ctx context.Context) (string, error) {
 	return randomstring.New(u.length), nil
 }
+
+func (u *urlGenerator) NextN(ctx context.Context, n int) ([]string, error) {
+	shortURLs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		shortURLs = append(shortURLs, randomstring.New(u.length))
+	}
+
+	return shortURLs, nil
+}
@@ -0,0 +1,103 @@
+// Random short URL generator, it needs no coordination between nodes.
+// Collisions are possible, they are rejected by unique index of storage and creation is retried
+package randgen
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/shalimski/shortener/internal/ports"
+)
+
+// randomValues is number of values of one random byte, every char takes one byte
+const randomValues = 256
+
+var (
+	ErrInvalidAlphabet = errors.New("alphabet must have at least 2 unique ascii chars")
+	ErrInvalidLength   = errors.New("length of short url must be positive")
+)
+
+type urlGenerator struct {
+	alphabet string
+	length   int
+	limit    int       // random bytes not less than limit are skipped, so every char has the same probability
+	random   io.Reader // source of randomness
+}
+
+// NewURLGenerator create instance of generator of random short urls of length chars of alphabet
+func NewURLGenerator(alphabet string, length int) (ports.ShortURLGenerator, error) {
+	if len(alphabet) < 2 {
+		return nil, ErrInvalidAlphabet
+	}
+
+	seen := make(map[rune]struct{}, len(alphabet))
+	for _, c := range alphabet {
+		if _, ok := seen[c]; ok || c >= utf8.RuneSelf {
+			return nil, ErrInvalidAlphabet
+		}
+
+		seen[c] = struct{}{}
+	}
+
+	if length < 1 {
+		return nil, ErrInvalidLength
+	}
+
+	return &urlGenerator{
+		alphabet: alphabet,
+		length:   length,
+		limit:    randomValues - randomValues%len(alphabet),
+		random:   rand.Reader,
+	}, nil
+}
+
+// Next random short URL
+func (u *urlGenerator) Next(ctx context.Context) (string, error) {
+	return u.generate()
+}
+
+// NextN random short URLs
+func (u *urlGenerator) NextN(ctx context.Context, n int) ([]string, error) {
+	shortURLs := make([]string, 0, n)
+
+	for i := 0; i < n; i++ {
+		shortURL, err := u.generate()
+		if err != nil {
+			return nil, err
+		}
+
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	return shortURLs, nil
+}
+
+func (u *urlGenerator) generate() (string, error) {
+	b := make([]byte, u.length)
+	buf := make([]byte, u.length)
+
+	for i := 0; i < len(b); {
+		if _, err := io.ReadFull(u.random, buf); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+
+		for _, r := range buf {
+			if int(r) >= u.limit {
+				continue
+			}
+
+			b[i] = u.alphabet[int(r)%len(u.alphabet)]
+			i++
+
+			if i == len(b) {
+				break
+			}
+		}
+	}
+
+	return string(b), nil
+}
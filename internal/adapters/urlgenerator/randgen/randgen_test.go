@@ -0,0 +1,74 @@
+package randgen
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const base62 = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func TestNext(t *testing.T) {
+	gen, err := NewURLGenerator(base62, 7)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	unique := make(map[string]struct{})
+
+	for i := 0; i < 1000; i++ {
+		shortURL, err := gen.Next(ctx)
+		require.NoError(t, err)
+
+		assert.Len(t, shortURL, 7)
+
+		for _, c := range shortURL {
+			assert.True(t, strings.ContainsRune(base62, c))
+		}
+
+		unique[shortURL] = struct{}{}
+	}
+
+	assert.Len(t, unique, 1000)
+}
+
+func TestNextN(t *testing.T) {
+	gen, err := NewURLGenerator("ab", 3)
+	require.NoError(t, err)
+
+	shortURLs, err := gen.NextN(context.Background(), 10)
+	require.NoError(t, err)
+
+	assert.Len(t, shortURLs, 10)
+}
+
+func TestSkipsBiasedBytes(t *testing.T) {
+	gen, err := NewURLGenerator("abc", 4)
+	require.NoError(t, err)
+
+	// 255 is above largest multiple of 3, so it is skipped
+	u := gen.(*urlGenerator) //nolint:forcetypeassert // test
+	u.random = bytes.NewReader([]byte{0, 255, 1, 2, 255, 255, 3, 4, 5})
+
+	shortURL, err := u.Next(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "abca", shortURL)
+
+	_, err = u.Next(context.Background())
+	assert.Error(t, err)
+}
+
+func TestNewURLGenerator(t *testing.T) {
+	_, err := NewURLGenerator("a", 7)
+	assert.ErrorIs(t, err, ErrInvalidAlphabet)
+
+	_, err = NewURLGenerator("abca", 7)
+	assert.ErrorIs(t, err, ErrInvalidAlphabet)
+
+	_, err = NewURLGenerator("abc", 0)
+	assert.ErrorIs(t, err, ErrInvalidLength)
+}
@@ -2,90 +2,201 @@ package app
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/shalimski/shortener/config"
-	"github.com/shalimski/shortener/internal/adapters/cache"
-
-	"github.com/shalimski/shortener/internal/adapters/repository/urlrepo"
-	"github.com/shalimski/shortener/internal/adapters/urlgenerator/generator"
+	"github.com/shalimski/shortener/internal/adapters/analytics"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/metrics"
 	"github.com/shalimski/shortener/internal/services"
 	"github.com/shalimski/shortener/internal/web"
-	"github.com/shalimski/shortener/pkg/coordinator"
+	"github.com/shalimski/shortener/pkg/clientip"
 	"github.com/shalimski/shortener/pkg/httpserver"
 	"github.com/shalimski/shortener/pkg/logger"
-	"github.com/shalimski/shortener/pkg/mongodb"
 	"go.uber.org/zap"
 )
 
+// apiPrefix is path of API, redirects of short urls share it
+const apiPrefix = "/api/v1"
+
 func Run(cfg *config.Config) {
 	ctx := context.Background()
 	log := logger.NewLogger()
 	log.With(zap.String("node", cfg.Node.Name))
 	log.Info(ctx, "starting app...")
 
+	if !domain.IsRedirectCode(cfg.App.RedirectCode) {
+		log.Fatal("invalid default redirect code", zap.Int("code", cfg.App.RedirectCode))
+
+		return
+	}
+
+	m := metrics.New()
+
 	// Database init
-	mongoClient, err := mongodb.NewClient(cfg)
+	storage, err := OpenStorage(ctx, cfg, log)
 	if err != nil {
-		log.Fatal("failed to connect MongoDB", zap.Error(err))
+		log.Fatal("failed to init storage", zap.Error(err))
 
 		return
 	}
 
-	db := urlrepo.NewURLRepo(mongoClient.Database(cfg.Mongo.Database))
+	defer storage.Close() //nolint:errcheck // simple
 
-	log.Info(ctx, "MongoDB initialized")
+	log.Info(ctx, "storage initialized", zap.String("backend", cfg.Backend.Storage))
 
-	// Coordinator for distributed counter
-	counter, err := coordinator.NewCoordinator(cfg.App.EtcdEndpoints)
+	// Generator
+	urlgen, shutdownGenerator, err := newGenerator(cfg, m)
 	if err != nil {
-		log.Fatal("failed to start distributed counter", zap.Error(err))
+		log.Fatal("failed to init url generator", zap.Error(err))
 
 		return
 	}
 
-	defer counter.Shutdown()
+	defer shutdownGenerator()
 
-	log.Info(ctx, "distributed counter initialized")
+	log.Info(ctx, "url generator initialized", zap.String("backend", cfg.Backend.Generator))
 
-	// Generator
-	urlgen, err := generator.NewURLGenerator(counter)
+	cacher, shutdownCache, err := newCache(cfg, log)
 	if err != nil {
-		log.Error(ctx, "failed to init url generator", zap.Error(err))
+		log.Fatal("failed to init cache", zap.Error(err))
 
 		return
 	}
 
-	log.Info(ctx, "url generator initialized")
+	defer shutdownCache()
+
+	log.Info(ctx, "cache initialized", zap.String("backend", cfg.Backend.Cache))
+
+	// Click analytics
+	recorder := analytics.NewRecorder(log, storage.Clicks, cfg.Analytics)
 
-	redis := cache.NewCache(cfg)
+	log.Info(ctx, "click recorder initialized")
+
+	// Wrong passwords of protected urls
+	attempts, shutdownAttempts, err := newPasswordAttempts(cfg)
+	if err != nil {
+		log.Fatal("failed to init password attempts", zap.Error(err))
+
+		return
+	}
+
+	defer shutdownAttempts()
 
 	// Main service
-	service := services.NewService(log, db, urlgen, redis)
+	opts := []services.Option{
+		services.Dedup(cfg.App.Dedup),
+		services.Metrics(m),
+		services.NotFoundTTL(cfg.Cache.NotFoundTTL),
+		services.CreateAttempts(cfg.App.CreateAttempts),
+		services.Audit(storage.Audit),
+		services.PasswordAttempts(attempts),
+	}
+
+	if cfg.Cache.BloomFilter {
+		filter, err := newFilter(ctx, cfg, storage.URLs)
+		if err != nil {
+			log.Fatal("failed to fill bloom filter", zap.Error(err))
+
+			return
+		}
+
+		opts = append(opts, services.Filter(filter))
+
+		log.Info(ctx, "bloom filter initialized")
+	}
+
+	service := services.NewService(log, storage.URLs, urlgen, cacher, storage.Clicks, opts...)
 	log.Info(ctx, "service initialized")
 
-	h := web.NewHandler(service, log)
+	auth := services.NewAuthService(log, storage.Keys)
+
+	ips, err := clientip.New(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		log.Fatal("failed to parse trusted proxies", zap.Error(err))
+
+		return
+	}
+
+	h := web.NewHandler(cfg, service, recorder, ips, log)
+
+	// Rate limits of clients
+	limitCreate, limitCreateBatch, limitRedirect, limitManagement := noLimit, noLimit, noLimit, noLimit
+
+	if cfg.RateLimit.Enabled {
+		limits, shutdownLimiters, err := newLimiters(cfg)
+		if err != nil {
+			log.Fatal("failed to init rate limiters", zap.Error(err))
+
+			return
+		}
+
+		defer shutdownLimiters()
+
+		limitCreate = web.RateLimit(limits.create, ips, log)
+		limitCreateBatch = web.RateLimitPerItem(limits.create, ips, log)
+		limitRedirect = web.RateLimit(limits.redirect, ips, log)
+		limitManagement = web.RateLimit(limits.management, ips, log)
+
+		log.Info(ctx, "rate limiters initialized", zap.String("backend", cfg.Backend.RateLimit))
+	}
 
 	r := chi.NewRouter()
 
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Heartbeat("/ping"))
-	r.Mount("/debug", middleware.Profiler())
-	r.Route("/api/v1", func(r chi.Router) {
+	r.Route(apiPrefix, func(r chi.Router) {
 		r.Use(middleware.RequestID)
-		r.Use(logger.Middleware(log))
-		r.Post("/shorten", h.Create)
-		r.Get("/{shortURL}", h.Find)
-		r.Delete("/{shortURL}", h.Delete)
+		r.Use(logger.Middleware(log, m.ObserveRequest))
+		r.With(limitRedirect).Get("/{shortURL}", h.Find)
+		r.With(limitRedirect).Post("/{shortURL}", h.Unlock)
+
+		// Management API
+		r.Group(func(r chi.Router) {
+			// limited by address before authentication, so invalid api keys are limited too
+			r.Use(limitManagement)
+
+			if cfg.Auth.Enabled {
+				r.Use(web.Auth(auth, log))
+			}
+
+			r.With(limitCreate).Post("/shorten", h.Create)
+			r.With(limitCreateBatch).Post("/shorten/batch", h.CreateBatch)
+			r.Get("/links", h.List)
+			r.Get("/{shortURL}/stats", h.Stats)
+			r.Get("/{shortURL}/info", h.Info)
+			r.Patch("/{shortURL}", h.Update)
+			r.Delete("/{shortURL}", h.Delete)
+			r.Post("/{shortURL}/restore", h.Restore)
+			r.Get("/{shortURL}/history", h.History)
+		})
 	})
 
+	if err := reserveStaticRoutes(r, apiPrefix); err != nil {
+		log.Fatal("failed to reserve short urls of api routes", zap.Error(err))
+
+		return
+	}
+
 	httpServer := httpserver.New(r, httpserver.Port(cfg.HTTP.Port))
 	log.Info(ctx, "http service started on port: "+cfg.HTTP.Port)
 
+	// Debug server with metrics and profiler
+	dr := chi.NewRouter()
+
+	dr.Use(middleware.Recoverer)
+	dr.Handle("/metrics", m.Handler())
+	dr.Mount("/debug", middleware.Profiler())
+
+	debugServer := httpserver.New(dr, httpserver.Port(cfg.HTTP.DebugPort))
+	log.Info(ctx, "debug service started on port: "+cfg.HTTP.DebugPort)
+
 	log.Info(ctx, "-- Ready to accept connections --")
 
 	// Waiting signal
@@ -97,6 +208,8 @@ func Run(cfg *config.Config) {
 		log.Info(ctx, "signal: "+s.String())
 	case servererr := <-httpServer.Notify():
 		log.Error(ctx, "httpServer was stopped", zap.Error(servererr))
+	case servererr := <-debugServer.Notify():
+		log.Error(ctx, "debugServer was stopped", zap.Error(servererr))
 	}
 
 	// Shutdown
@@ -105,5 +218,32 @@ func Run(cfg *config.Config) {
 		log.Error(ctx, "failed to shutdown", zap.Error(err))
 	}
 
-	counter.Shutdown()
+	err = debugServer.Shutdown()
+	if err != nil {
+		log.Error(ctx, "failed to shutdown debug server", zap.Error(err))
+	}
+
+	recorder.Shutdown()
+}
+
+// reserveStaticRoutes reserves first segments of static routes under prefix,
+// they shadow redirects of short urls with the same names
+func reserveStaticRoutes(r chi.Routes, prefix string) error {
+	return chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, prefix+"/") {
+			return nil
+		}
+
+		segment, _, _ := strings.Cut(strings.TrimPrefix(route, prefix+"/"), "/")
+		if segment != "" && !strings.ContainsAny(segment, "{*") {
+			domain.ReserveShortURL(segment)
+		}
+
+		return nil
+	})
+}
+
+// noLimit is used in place of rate limit middleware when rate limits are disabled
+func noLimit(next http.Handler) http.Handler {
+	return next
 }
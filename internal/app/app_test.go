@@ -0,0 +1,36 @@
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveStaticRoutes(t *testing.T) {
+	handler := func(http.ResponseWriter, *http.Request) {}
+
+	r := chi.NewRouter()
+	r.Route(apiPrefix, func(r chi.Router) {
+		r.Get("/{shortURL}", handler)
+		r.Post("/{shortURL}", handler)
+		r.Group(func(r chi.Router) {
+			r.Post("/shorten", handler)
+			r.Post("/shorten/batch", handler)
+			r.Get("/links", handler)
+			r.Get("/{shortURL}/stats", handler)
+		})
+	})
+	r.Get("/status", handler)
+
+	require.NoError(t, reserveStaticRoutes(r, apiPrefix))
+
+	assert.True(t, domain.IsReservedShortURL("shorten"), "password form of short url is shadowed by POST /shorten")
+	assert.True(t, domain.IsReservedShortURL("links"))
+	assert.False(t, domain.IsReservedShortURL("batch"))
+	assert.False(t, domain.IsReservedShortURL("stats"))
+	assert.False(t, domain.IsReservedShortURL("status"), "routes out of api do not shadow short urls")
+}
@@ -0,0 +1,313 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/shalimski/shortener/config"
+	"github.com/shalimski/shortener/internal/adapters/cache"
+	"github.com/shalimski/shortener/internal/adapters/repository/auditrepo"
+	"github.com/shalimski/shortener/internal/adapters/repository/clickrepo"
+	"github.com/shalimski/shortener/internal/adapters/repository/filedb"
+	"github.com/shalimski/shortener/internal/adapters/repository/keyrepo"
+	"github.com/shalimski/shortener/internal/adapters/repository/migrations"
+	"github.com/shalimski/shortener/internal/adapters/repository/urlrepo"
+	"github.com/shalimski/shortener/internal/adapters/urlgenerator/generator"
+	"github.com/shalimski/shortener/internal/adapters/urlgenerator/randgen"
+	"github.com/shalimski/shortener/internal/metrics"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/bloom"
+	"github.com/shalimski/shortener/pkg/coordinator"
+	"github.com/shalimski/shortener/pkg/filecounter"
+	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/shalimski/shortener/pkg/mongodb"
+	"github.com/shalimski/shortener/pkg/ratelimit"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/zap"
+)
+
+// names of backends in config
+const (
+	backendMongo  = "mongo"
+	backendFile   = "file"
+	backendEtcd   = "etcd"
+	backendRedis  = "redis"
+	backendMemory = "memory"
+
+	generatorCounter = "counter"
+	generatorRandom  = "random"
+)
+
+const counterFile = "counter"
+
+// rateLimitPrefix separates buckets of rate limiter from cached urls in redis
+const rateLimitPrefix = "shortener:ratelimit:"
+
+// passwordAttemptsPrefix separates counters of wrong passwords from cached urls in redis
+const passwordAttemptsPrefix = "shortener:attempts:"
+
+// bloomFalsePositiveRate is a share of missing short urls looked up in cache and storage
+const bloomFalsePositiveRate = 0.01
+
+// Storage is a set of repositories of configured storage backend
+type Storage struct {
+	URLs   ports.Repository
+	Clicks ports.ClickRepository
+	Keys   ports.APIKeyRepository
+	Audit  ports.AuditRepository
+
+	close func() error
+}
+
+// OpenStorage connects to storage selected by STORAGE_BACKEND, pending migrations of MongoDB are applied
+func OpenStorage(ctx context.Context, cfg *config.Config, log *logger.Logger) (*Storage, error) {
+	switch cfg.Backend.Storage {
+	case backendMongo:
+		return openMongoStorage(ctx, cfg, log)
+	case backendFile:
+		db, err := filedb.Open(cfg.Backend.DataDir)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Storage{
+			URLs:   db.URLs(),
+			Clicks: db.Clicks(),
+			Keys:   db.Keys(),
+			Audit:  db.Audit(),
+			close:  db.Close,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend.Storage)
+	}
+}
+
+// OpenKeys opens only api key repository for cmd/apikey, data directory of file storage is not locked,
+// so keys are created while server is running
+func OpenKeys(ctx context.Context, cfg *config.Config, log *logger.Logger) (repo ports.APIKeyRepository, closeFn func() error, err error) {
+	if cfg.Backend.Storage == backendFile {
+		return filedb.OpenKeys(cfg.Backend.DataDir)
+	}
+
+	storage, err := OpenStorage(ctx, cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return storage.Keys, storage.Close, nil
+}
+
+func openMongoStorage(ctx context.Context, cfg *config.Config, log *logger.Logger) (*Storage, error) {
+	mongoClient, err := mongodb.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect MongoDB: %w", err)
+	}
+
+	s := &Storage{
+		close: func() error {
+			return mongoClient.Disconnect(context.Background())
+		},
+	}
+
+	db := mongoClient.Database(cfg.Mongo.Database)
+
+	if err := migrate(ctx, cfg, db, log); err != nil {
+		s.Close() //nolint:errcheck // already failed
+
+		return nil, err
+	}
+
+	s.URLs = urlrepo.NewURLRepo(db)
+	s.Clicks = clickrepo.NewClickRepo(db)
+	s.Keys = keyrepo.NewKeyRepo(db)
+	s.Audit = auditrepo.NewAuditRepo(db)
+
+	return s, nil
+}
+
+// migrate applies migrations of MongoDB under etcd lock when etcd is used by counter,
+// otherwise nodes may run migrations concurrently, they are safe to rerun
+func migrate(ctx context.Context, cfg *config.Config, db *mongo.Database, log *logger.Logger) error {
+	if cfg.Backend.Generator != generatorCounter || cfg.Backend.Counter != backendEtcd {
+		return migrations.Run(ctx, db, nil, log)
+	}
+
+	c, err := coordinator.NewCoordinator(cfg.App.EtcdEndpoints)
+	if err != nil {
+		return fmt.Errorf("failed to connect etcd for migrations: %w", err)
+	}
+
+	defer c.Shutdown()
+
+	return migrations.Run(ctx, db, c, log)
+}
+
+// Close connection or files of storage
+func (s *Storage) Close() error {
+	return s.close()
+}
+
+// newGenerator creates short url generator selected by GENERATOR_BACKEND, shutdown releases its resources
+func newGenerator(cfg *config.Config, m *metrics.Metrics) (urlgen ports.ShortURLGenerator, shutdown func(), err error) {
+	switch cfg.Backend.Generator {
+	case generatorCounter:
+		encoding, err := generator.NewEncoding(cfg.App.Alphabet, cfg.App.MinLength, cfg.App.MaxLength)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid short url encoding: %w", err)
+		}
+
+		counter, shutdown, err := newCounter(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to start counter: %w", err)
+		}
+
+		urlgen, err := generator.NewURLGenerator(m.InstrumentCounter(counter),
+			generator.Interval(cfg.App.Interval),
+			generator.UseEncoding(encoding),
+			generator.Obfuscate(cfg.App.ObfuscationKey),
+		)
+		if err != nil {
+			shutdown()
+
+			return nil, nil, err
+		}
+
+		if gen, ok := urlgen.(metrics.Remainer); ok {
+			m.RegisterGenerator(gen)
+		}
+
+		return urlgen, shutdown, nil
+	case generatorRandom:
+		if cfg.App.ShortURLLength > cfg.App.MaxLength {
+			return nil, nil, fmt.Errorf("short url length %d exceeds max length %d", cfg.App.ShortURLLength, cfg.App.MaxLength)
+		}
+
+		urlgen, err := randgen.NewURLGenerator(cfg.App.Alphabet, cfg.App.ShortURLLength)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return urlgen, func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown generator backend %q", cfg.Backend.Generator)
+	}
+}
+
+// newCounter creates counter selected by COUNTER_BACKEND, shutdown releases its resources
+func newCounter(cfg *config.Config) (counter generator.Counter, shutdown func(), err error) {
+	switch cfg.Backend.Counter {
+	case backendEtcd:
+		c, err := coordinator.NewCoordinator(cfg.App.EtcdEndpoints)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return c, c.Shutdown, nil
+	case backendFile:
+		c, err := filecounter.New(filepath.Join(cfg.Backend.DataDir, counterFile))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return c, func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown counter backend %q", cfg.Backend.Counter)
+	}
+}
+
+// newCache creates cache selected by CACHE_BACKEND, shutdown releases its resources
+func newCache(cfg *config.Config, log *logger.Logger) (cacher ports.Cacher, shutdown func(), err error) {
+	switch cfg.Backend.Cache {
+	case backendRedis:
+		if cfg.Cache.LocalSize <= 0 {
+			return cache.NewCache(cfg), func() {}, nil
+		}
+
+		c := cache.NewTieredCache(cfg, log)
+
+		return c, func() {
+			if err := c.Close(); err != nil {
+				log.Error(context.Background(), "failed to close cache", zap.Error(err))
+			}
+		}, nil
+	case backendMemory:
+		return cache.NewMemoryCache(cfg.Cache.LocalSize, 0), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown cache backend %q", cfg.Backend.Cache)
+	}
+}
+
+// limiters are rate limiters of endpoints
+type limiters struct {
+	create     ratelimit.Limiter
+	redirect   ratelimit.Limiter
+	management ratelimit.Limiter // all management requests by address of client before authentication
+}
+
+// newLimiters creates limiters of create, redirect and management endpoints selected by RATE_LIMIT_BACKEND,
+// shutdown releases their resources
+func newLimiters(cfg *config.Config) (l limiters, shutdown func(), err error) {
+	rl := cfg.RateLimit
+	if rl.CreateRate <= 0 || rl.RedirectRate <= 0 || rl.ManagementRate <= 0 ||
+		rl.CreateBurst < 1 || rl.RedirectBurst < 1 || rl.ManagementBurst < 1 {
+		return limiters{}, nil, fmt.Errorf("rate limit rates must be positive and bursts at least 1")
+	}
+
+	switch cfg.Backend.RateLimit {
+	case backendMemory:
+		return limiters{
+			create:     ratelimit.NewMemory(rl.CreateRate, rl.CreateBurst),
+			redirect:   ratelimit.NewMemory(rl.RedirectRate, rl.RedirectBurst),
+			management: ratelimit.NewMemory(rl.ManagementRate, rl.ManagementBurst),
+		}, func() {}, nil
+	case backendRedis:
+		rdb := redis.NewClient(&redis.Options{
+			Addr:     cfg.Redis.DSN,
+			Password: cfg.Redis.Password,
+		})
+
+		return limiters{
+			create:     ratelimit.NewRedis(rdb, rateLimitPrefix+"create:", rl.CreateRate, rl.CreateBurst),
+			redirect:   ratelimit.NewRedis(rdb, rateLimitPrefix+"redirect:", rl.RedirectRate, rl.RedirectBurst),
+			management: ratelimit.NewRedis(rdb, rateLimitPrefix+"management:", rl.ManagementRate, rl.ManagementBurst),
+		}, func() {
+			rdb.Close() //nolint:errcheck // shutting down
+		}, nil
+	default:
+		return limiters{}, nil, fmt.Errorf("unknown rate limit backend %q", cfg.Backend.RateLimit)
+	}
+}
+
+// newPasswordAttempts creates counter of wrong passwords of protected urls, it is kept in redis
+// when redis is used by cache or rate limiter, so every node counts the same attempts
+func newPasswordAttempts(cfg *config.Config) (attempts ratelimit.Attempts, shutdown func(), err error) {
+	if cfg.App.PasswordAttempts < 1 || cfg.App.PasswordWindow <= 0 {
+		return nil, nil, fmt.Errorf("password attempts must be at least 1 and window positive")
+	}
+
+	if cfg.Backend.Cache != backendRedis && cfg.Backend.RateLimit != backendRedis {
+		return ratelimit.NewMemoryAttempts(cfg.App.PasswordAttempts, cfg.App.PasswordWindow), func() {}, nil
+	}
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.DSN,
+		Password: cfg.Redis.Password,
+	})
+
+	return ratelimit.NewRedisAttempts(rdb, passwordAttemptsPrefix, cfg.App.PasswordAttempts, cfg.App.PasswordWindow), func() {
+		rdb.Close() //nolint:errcheck // shutting down
+	}, nil
+}
+
+// newFilter creates bloom filter of all stored short urls
+func newFilter(ctx context.Context, cfg *config.Config, repo ports.Repository) (ports.ShortURLFilter, error) {
+	filter := bloom.New(cfg.Cache.BloomCapacity, bloomFalsePositiveRate)
+
+	if err := repo.EachShortURL(ctx, filter.Add); err != nil {
+		return nil, err
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// APIKey authenticates clients of management API, secret of key is never stored
+type APIKey struct {
+	Hash      string    `json:"-"` // hex encoded sha256 of secret
+	Owner     string    `json:"owner"`
+	Admin     bool      `json:"admin"` // admin can manage links of any owner
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CanManage reports whether key owner is allowed to change url and read its metadata, stats and history
+func (k APIKey) CanManage(url URL) bool {
+	return k.Admin || k.Owner == url.Owner
+}
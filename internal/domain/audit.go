@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// AuditAction is a change of url recorded in audit log
+type AuditAction string
+
+const (
+	AuditCreated  AuditAction = "created"
+	AuditUpdated  AuditAction = "updated"
+	AuditDeleted  AuditAction = "deleted"
+	AuditRestored AuditAction = "restored"
+)
+
+// AuditEvent is a record of append-only audit log of url
+type AuditEvent struct {
+	ShortURL string      `json:"short_url"`
+	Action   AuditAction `json:"action"`
+	Actor    string      `json:"actor"`              // owner of api key made change
+	LongURL  string      `json:"long_url,omitempty"` // long url after change
+	At       time.Time   `json:"at"`
+}
+
+// NewAuditEvent creates event of url changed by actor now
+func NewAuditEvent(url URL, action AuditAction, actor string, now time.Time) AuditEvent {
+	return AuditEvent{
+		ShortURL: url.ShortURL,
+		Action:   action,
+		Actor:    actor,
+		LongURL:  url.LongURL,
+		At:       now.UTC(),
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// DayLayout formats days of daily stats
+const DayLayout = "2006-01-02"
+
+// MaxReferrers limits referrers of stats to the most clicked ones
+const MaxReferrers = 10
+
+// Click is a single redirect by short url
+type Click struct {
+	ShortURL  string    `json:"short_url"`
+	Timestamp time.Time `json:"timestamp"`
+	Referrer  string    `json:"referrer"`
+	UserAgent string    `json:"user_agent"`
+	Country   string    `json:"country"`
+}
+
+// Stats is aggregated clicks of short url
+type Stats struct {
+	ShortURL  string         `json:"short_url"`
+	Total     int64          `json:"total"`
+	Daily     []DailyStat    `json:"daily"`
+	Referrers []ReferrerStat `json:"referrers"` // top MaxReferrers by clicks
+}
+
+type DailyStat struct {
+	Day    string `json:"day"` // YYYY-MM-DD in UTC
+	Clicks int64  `json:"clicks"`
+}
+
+type ReferrerStat struct {
+	Referrer string `json:"referrer"`
+	Clicks   int64  `json:"clicks"`
+}
@@ -1,8 +1,24 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrFailedToCreate = errors.New("failed to create shortURL")
-	ErrNotFound       = errors.New("shortURL not found")
+	ErrFailedToCreate  = errors.New("failed to create shortURL")
+	ErrNotFound        = errors.New("shortURL not found")
+	ErrAlreadyExists   = errors.New("shortURL already exists")
+	ErrExpired         = errors.New("shortURL expired")
+	ErrDeleted         = errors.New("shortURL deleted")
+	ErrNotDeleted      = errors.New("shortURL is not deleted")
+	ErrKeyReused       = errors.New("idempotency key reused with another longURL")
+	ErrUnauthorized    = errors.New("invalid api key")
+	ErrForbidden       = errors.New("shortURL owned by another api key")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrWrongPassword   = errors.New("wrong password of shortURL")
+	ErrTooManyAttempts = errors.New("too many wrong passwords of shortURL")
+
+	// ErrCachedNotFound is returned by cache when missing short url was cached
+	ErrCachedNotFound = fmt.Errorf("%w in cache", ErrNotFound)
 )
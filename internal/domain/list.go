@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ListSort orders listed urls, urls with equal keys are ordered by short url
+type ListSort string
+
+const (
+	SortCreatedAsc   ListSort = "created_at"
+	SortCreatedDesc  ListSort = "-created_at"
+	SortShortURLAsc  ListSort = "short_url"
+	SortShortURLDesc ListSort = "-short_url"
+)
+
+// IsValid reports whether sort is supported
+func (s ListSort) IsValid() bool {
+	switch s {
+	case SortCreatedAsc, SortCreatedDesc, SortShortURLAsc, SortShortURLDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// Desc reports whether urls are listed in descending order
+func (s ListSort) Desc() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+// ByCreated reports whether urls are ordered by creation time
+func (s ListSort) ByCreated() bool {
+	return s == SortCreatedAsc || s == SortCreatedDesc
+}
+
+// Less reports whether url a is listed before url b
+func (s ListSort) Less(a, b URL) bool {
+	if s.ByCreated() && !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt) != s.Desc()
+	}
+
+	if a.ShortURL == b.ShortURL {
+		return false
+	}
+
+	return (a.ShortURL < b.ShortURL) != s.Desc()
+}
+
+// ListFilter selects listed urls, zero fields do not filter
+type ListFilter struct {
+	Owner         string
+	CreatedAfter  time.Time // inclusive
+	CreatedBefore time.Time // exclusive
+	Domain        string    // host of long url, case insensitive
+	Tag           string
+	Deleted       bool // list only deleted urls instead of not deleted ones
+}
+
+// Match reports whether url passes filter
+func (f ListFilter) Match(u URL) bool {
+	if u.IsDeleted() != f.Deleted {
+		return false
+	}
+
+	if f.Owner != "" && u.Owner != f.Owner {
+		return false
+	}
+
+	if !f.CreatedAfter.IsZero() && u.CreatedAt.Before(f.CreatedAfter) {
+		return false
+	}
+
+	if !f.CreatedBefore.IsZero() && !u.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+
+	if f.Domain != "" && !strings.EqualFold(longURLHost(u.LongURL), f.Domain) {
+		return false
+	}
+
+	if f.Tag != "" && !u.HasTag(f.Tag) {
+		return false
+	}
+
+	return true
+}
+
+// HasTag reports whether url is tagged by tag
+func (u URL) HasTag(tag string) bool {
+	for _, t := range u.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func longURLHost(longURL string) string {
+	parsed, err := url.Parse(longURL)
+	if err != nil {
+		return ""
+	}
+
+	return parsed.Hostname()
+}
+
+// ListCursor is a position after last url of listed page
+type ListCursor struct {
+	Sort      ListSort  `json:"o"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	ShortURL  string    `json:"s"`
+}
+
+// NewListCursor returns position after url in order of sort
+func NewListCursor(sort ListSort, u URL) ListCursor {
+	return ListCursor{Sort: sort, CreatedAt: u.CreatedAt, ShortURL: u.ShortURL}
+}
+
+// Encode cursor to opaque token
+func (c ListCursor) Encode() string {
+	data, _ := json.Marshal(c) //nolint:errchkjson // cursor always marshals
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeListCursor decodes token returned by Encode
+func DecodeListCursor(token string) (ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ListCursor{}, ErrInvalidCursor
+	}
+
+	var c ListCursor
+	if err := json.Unmarshal(data, &c); err != nil || !c.Sort.IsValid() || c.ShortURL == "" {
+		return ListCursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Precedes reports whether url is listed after cursor
+func (c ListCursor) Precedes(u URL) bool {
+	return c.Sort.Less(URL{ShortURL: c.ShortURL, CreatedAt: c.CreatedAt}, u)
+}
+
+// ListQuery selects page of urls
+type ListQuery struct {
+	Filter ListFilter
+	Sort   ListSort
+	After  *ListCursor // nil means first page
+	Limit  int
+}
+
+// ListPage is a page of listed urls, empty next cursor means the page is last
+type ListPage struct {
+	URLs       []URL
+	NextCursor string
+}
@@ -1,6 +1,91 @@
 package domain
 
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
 type URL struct {
-	ShortURL string `json:"short_url"`
-	LongURL  string `json:"long_url"`
+	ShortURL       string     `json:"short_url"`
+	LongURL        string     `json:"long_url"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`    // nil means url never expires
+	IdempotencyKey string     `json:"-"`                       // key of request created url
+	Owner          string     `json:"owner,omitempty"`         // owner of api key created url
+	RedirectCode   int        `json:"redirect_code,omitempty"` // zero means default redirect code
+	Title          string     `json:"title,omitempty"`
+	Tags           []string   `json:"tags,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`           // set by storage on creation
+	UpdatedAt      time.Time  `json:"updated_at"`           // set by storage on creation and update
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"` // nil means url is not deleted
+	DeletedBy      string     `json:"deleted_by,omitempty"` // owner of api key deleted url
+	PasswordHash   string     `json:"-"`                    // bcrypt hash of password, empty means url is public
+}
+
+// reservedShortURLs are paths of API sharing prefix with redirects, short urls with such names are unreachable.
+// They are reserved from registered routes before server starts
+var reservedShortURLs = struct { //nolint:gochecknoglobals // set of routes of process
+	sync.RWMutex
+	names map[string]bool
+}{names: make(map[string]bool)}
+
+// ReserveShortURL marks short url shadowed by path of API
+func ReserveShortURL(shortURL string) {
+	reservedShortURLs.Lock()
+	defer reservedShortURLs.Unlock()
+
+	reservedShortURLs.names[shortURL] = true
+}
+
+// IsReservedShortURL reports whether short url is shadowed by path of API
+func IsReservedShortURL(shortURL string) bool {
+	reservedShortURLs.RLock()
+	defer reservedShortURLs.RUnlock()
+
+	return reservedShortURLs.names[shortURL]
+}
+
+// IsRedirectCode reports whether code is supported HTTP redirect status
+func IsRedirectCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsExpired reports whether url has an expiration time and it has passed
+func (u URL) IsExpired(now time.Time) bool {
+	return u.ExpiresAt != nil && !now.Before(*u.ExpiresAt)
+}
+
+// IsDeleted reports whether url is a tombstone of deleted url
+func (u URL) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// IsProtected reports whether url redirects only after its password is entered
+func (u URL) IsProtected() bool {
+	return u.PasswordHash != ""
+}
+
+// TTL returns time left until url expiration, zero ttl means url never expires,
+// ok is false when url is already expired
+func (u URL) TTL(now time.Time) (ttl time.Duration, ok bool) {
+	if u.ExpiresAt == nil {
+		return 0, true
+	}
+
+	ttl = u.ExpiresAt.Sub(now)
+
+	return ttl, ttl > 0
+}
+
+// BatchResult is a result of shortening one of long urls in batch
+type BatchResult struct {
+	LongURL  string
+	ShortURL string
+	Err      error
 }
@@ -0,0 +1,173 @@
+// Prometheus metrics of service,
+// collectors are registered in own registry and exposed by Handler on debug port
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shalimski/shortener/internal/ports"
+)
+
+const namespace = "shortener"
+
+var _ ports.ServiceMetrics = (*Metrics)(nil)
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	cacheLookups    *prometheus.CounterVec
+	storageLookups  *prometheus.CounterVec
+	counterDuration prometheus.Histogram
+	counterFailures prometheus.Counter
+}
+
+// Counter is distributed counter of url generator
+type Counter interface {
+	NextCounter(context.Context) (int, error)
+}
+
+// Remainer reports how many short urls are left in current interval of generator
+type Remainer interface {
+	Remaining() int
+}
+
+// New create instance of Metrics with registered collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of handled HTTP requests.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of handled HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Number of cache lookups by result: hit or miss.",
+		}, []string{"result"}),
+		storageLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "storage",
+			Name:      "lookups_total",
+			Help:      "Number of storage lookups after cache miss by mode: executed or coalesced with concurrent lookup.",
+		}, []string{"mode"}),
+		counterDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "generator",
+			Name:      "next_counter_duration_seconds",
+			Help:      "Latency of reserving next interval from distributed counter.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		counterFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "generator",
+			Name:      "next_counter_failures_total",
+			Help:      "Number of failed reservations of next interval from distributed counter.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.cacheLookups,
+		m.storageLookups,
+		m.counterDuration,
+		m.counterFailures,
+	)
+
+	return m
+}
+
+// Handler exposes registered metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest counts handled request and its latency, labeled by chi route pattern
+func (m *Metrics) ObserveRequest(r *http.Request, status int, duration time.Duration) {
+	route := "unknown"
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		route = rctx.RoutePattern()
+	}
+
+	m.requests.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(route, r.Method).Observe(duration.Seconds())
+}
+
+// CacheLookup counts cache hit or miss
+func (m *Metrics) CacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+
+	m.cacheLookups.WithLabelValues(result).Inc()
+}
+
+// StorageLookup counts storage lookup executed or coalesced with concurrent lookup of the same short url
+func (m *Metrics) StorageLookup(coalesced bool) {
+	mode := "executed"
+	if coalesced {
+		mode = "coalesced"
+	}
+
+	m.storageLookups.WithLabelValues(mode).Inc()
+}
+
+// RegisterGenerator exposes short urls left in current interval of generator
+func (m *Metrics) RegisterGenerator(gen Remainer) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "generator",
+		Name:      "interval_remaining",
+		Help:      "Number of short urls left in current interval.",
+	}, func() float64 {
+		return float64(gen.Remaining())
+	}))
+}
+
+// InstrumentCounter wraps distributed counter to observe its latency and failures
+func (m *Metrics) InstrumentCounter(counter Counter) Counter {
+	return &instrumentedCounter{
+		counter: counter,
+		metrics: m,
+	}
+}
+
+type instrumentedCounter struct {
+	counter Counter
+	metrics *Metrics
+}
+
+func (c *instrumentedCounter) NextCounter(ctx context.Context) (int, error) {
+	start := time.Now()
+	next, err := c.counter.NextCounter(ctx)
+
+	c.metrics.counterDuration.Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		c.metrics.counterFailures.Inc()
+	}
+
+	return next, err
+}
@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shalimski/shortener/internal/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingCounter struct{}
+
+func (failingCounter) NextCounter(context.Context) (int, error) {
+	return 0, errors.New("etcd is unavailable")
+}
+
+type remainer int
+
+func (r remainer) Remaining() int {
+	return int(r)
+}
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+
+	m.CacheLookup(true)
+	m.CacheLookup(false)
+	m.CacheLookup(false)
+	m.StorageLookup(false)
+	m.StorageLookup(true)
+	m.RegisterGenerator(remainer(42))
+
+	_, err := m.InstrumentCounter(failingCounter{}).NextCounter(context.Background())
+	assert.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		`shortener_cache_lookups_total{result="hit"} 1`,
+		`shortener_cache_lookups_total{result="miss"} 2`,
+		`shortener_storage_lookups_total{mode="coalesced"} 1`,
+		`shortener_storage_lookups_total{mode="executed"} 1`,
+		`shortener_generator_interval_remaining 42`,
+		`shortener_generator_next_counter_failures_total 1`,
+	} {
+		assert.True(t, strings.Contains(body, line), line)
+	}
+}
@@ -7,6 +7,7 @@ package mock_ports
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/shalimski/shortener/internal/domain"
@@ -36,39 +37,67 @@ func (m *MockShortenerService) EXPECT() *MockShortenerServiceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockShortenerService) Create(ctx context.Context, longURL string) (string, error) {
+func (m *MockShortenerService) Create(ctx context.Context, url domain.URL) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, longURL)
+	ret := m.ctrl.Call(m, "Create", ctx, url)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockShortenerServiceMockRecorder) Create(ctx, longURL interface{}) *gomock.Call {
+func (mr *MockShortenerServiceMockRecorder) Create(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShortenerService)(nil).Create), ctx, url)
+}
+
+// CreateBatch mocks base method.
+func (m *MockShortenerService) CreateBatch(ctx context.Context, urls []domain.URL) []domain.BatchResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", ctx, urls)
+	ret0, _ := ret[0].([]domain.BatchResult)
+	return ret0
+}
+
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockShortenerServiceMockRecorder) CreateBatch(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockShortenerService)(nil).CreateBatch), ctx, urls)
+}
+
+// CreateWithAlias mocks base method.
+func (m *MockShortenerService) CreateWithAlias(ctx context.Context, url domain.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWithAlias", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateWithAlias indicates an expected call of CreateWithAlias.
+func (mr *MockShortenerServiceMockRecorder) CreateWithAlias(ctx, url interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockShortenerService)(nil).Create), ctx, longURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWithAlias", reflect.TypeOf((*MockShortenerService)(nil).CreateWithAlias), ctx, url)
 }
 
 // Delete mocks base method.
-func (m *MockShortenerService) Delete(ctx context.Context, shortURL string) error {
+func (m *MockShortenerService) Delete(ctx context.Context, shortURL string, key domain.APIKey) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, shortURL)
+	ret := m.ctrl.Call(m, "Delete", ctx, shortURL, key)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockShortenerServiceMockRecorder) Delete(ctx, shortURL interface{}) *gomock.Call {
+func (mr *MockShortenerServiceMockRecorder) Delete(ctx, shortURL, key interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockShortenerService)(nil).Delete), ctx, shortURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockShortenerService)(nil).Delete), ctx, shortURL, key)
 }
 
 // Find mocks base method.
-func (m *MockShortenerService) Find(ctx context.Context, shortURL string) (string, error) {
+func (m *MockShortenerService) Find(ctx context.Context, shortURL string) (domain.URL, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Find", ctx, shortURL)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(domain.URL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -79,6 +108,109 @@ func (mr *MockShortenerServiceMockRecorder) Find(ctx, shortURL interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockShortenerService)(nil).Find), ctx, shortURL)
 }
 
+// History mocks base method.
+func (m *MockShortenerService) History(ctx context.Context, shortURL string, key domain.APIKey) ([]domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", ctx, shortURL, key)
+	ret0, _ := ret[0].([]domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockShortenerServiceMockRecorder) History(ctx, shortURL, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockShortenerService)(nil).History), ctx, shortURL, key)
+}
+
+// Info mocks base method.
+func (m *MockShortenerService) Info(ctx context.Context, shortURL string, key domain.APIKey) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Info", ctx, shortURL, key)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockShortenerServiceMockRecorder) Info(ctx, shortURL, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockShortenerService)(nil).Info), ctx, shortURL, key)
+}
+
+// List mocks base method.
+func (m *MockShortenerService) List(ctx context.Context, query domain.ListQuery, key domain.APIKey) (domain.ListPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query, key)
+	ret0, _ := ret[0].(domain.ListPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockShortenerServiceMockRecorder) List(ctx, query, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockShortenerService)(nil).List), ctx, query, key)
+}
+
+// Restore mocks base method.
+func (m *MockShortenerService) Restore(ctx context.Context, shortURL string, key domain.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, shortURL, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockShortenerServiceMockRecorder) Restore(ctx, shortURL, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockShortenerService)(nil).Restore), ctx, shortURL, key)
+}
+
+// Stats mocks base method.
+func (m *MockShortenerService) Stats(ctx context.Context, shortURL string, key domain.APIKey) (domain.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", ctx, shortURL, key)
+	ret0, _ := ret[0].(domain.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockShortenerServiceMockRecorder) Stats(ctx, shortURL, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockShortenerService)(nil).Stats), ctx, shortURL, key)
+}
+
+// Unlock mocks base method.
+func (m *MockShortenerService) Unlock(ctx context.Context, shortURL, password, client string) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", ctx, shortURL, password, client)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockShortenerServiceMockRecorder) Unlock(ctx, shortURL, password, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockShortenerService)(nil).Unlock), ctx, shortURL, password, client)
+}
+
+// Update mocks base method.
+func (m *MockShortenerService) Update(ctx context.Context, shortURL, longURL string, key domain.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, shortURL, longURL, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockShortenerServiceMockRecorder) Update(ctx, shortURL, longURL, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockShortenerService)(nil).Update), ctx, shortURL, longURL, key)
+}
+
 // MockRepository is a mock of Repository interface.
 type MockRepository struct {
 	ctrl     *gomock.Controller
@@ -116,18 +248,46 @@ func (mr *MockRepositoryMockRecorder) Create(ctx, url interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, url)
 }
 
+// CreateMany mocks base method.
+func (m *MockRepository) CreateMany(ctx context.Context, urls []domain.URL) []error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", ctx, urls)
+	ret0, _ := ret[0].([]error)
+	return ret0
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockRepositoryMockRecorder) CreateMany(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockRepository)(nil).CreateMany), ctx, urls)
+}
+
 // Delete mocks base method.
-func (m *MockRepository) Delete(ctx context.Context, shortURL string) error {
+func (m *MockRepository) Delete(ctx context.Context, shortURL, deletedBy string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, shortURL)
+	ret := m.ctrl.Call(m, "Delete", ctx, shortURL, deletedBy)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockRepositoryMockRecorder) Delete(ctx, shortURL interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) Delete(ctx, shortURL, deletedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, shortURL, deletedBy)
+}
+
+// EachShortURL mocks base method.
+func (m *MockRepository) EachShortURL(ctx context.Context, fn func(string)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EachShortURL", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EachShortURL indicates an expected call of EachShortURL.
+func (mr *MockRepositoryMockRecorder) EachShortURL(ctx, fn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, shortURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EachShortURL", reflect.TypeOf((*MockRepository)(nil).EachShortURL), ctx, fn)
 }
 
 // Find mocks base method.
@@ -145,6 +305,79 @@ func (mr *MockRepositoryMockRecorder) Find(ctx, shortURL interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockRepository)(nil).Find), ctx, shortURL)
 }
 
+// FindByIdempotencyKey mocks base method.
+func (m *MockRepository) FindByIdempotencyKey(ctx context.Context, owner, key string) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIdempotencyKey", ctx, owner, key)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByIdempotencyKey indicates an expected call of FindByIdempotencyKey.
+func (mr *MockRepositoryMockRecorder) FindByIdempotencyKey(ctx, owner, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIdempotencyKey", reflect.TypeOf((*MockRepository)(nil).FindByIdempotencyKey), ctx, owner, key)
+}
+
+// FindByLongURL mocks base method.
+func (m *MockRepository) FindByLongURL(ctx context.Context, owner, longURL string) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByLongURL", ctx, owner, longURL)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByLongURL indicates an expected call of FindByLongURL.
+func (mr *MockRepositoryMockRecorder) FindByLongURL(ctx, owner, longURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByLongURL", reflect.TypeOf((*MockRepository)(nil).FindByLongURL), ctx, owner, longURL)
+}
+
+// List mocks base method.
+func (m *MockRepository) List(ctx context.Context, query domain.ListQuery) ([]domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, query)
+	ret0, _ := ret[0].([]domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), ctx, query)
+}
+
+// Restore mocks base method.
+func (m *MockRepository) Restore(ctx context.Context, shortURL string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, shortURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockRepositoryMockRecorder) Restore(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockRepository)(nil).Restore), ctx, shortURL)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(ctx context.Context, url domain.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), ctx, url)
+}
+
 // MockShortURLGenerator is a mock of ShortURLGenerator interface.
 type MockShortURLGenerator struct {
 	ctrl     *gomock.Controller
@@ -183,6 +416,21 @@ func (mr *MockShortURLGeneratorMockRecorder) Next(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Next", reflect.TypeOf((*MockShortURLGenerator)(nil).Next), ctx)
 }
 
+// NextN mocks base method.
+func (m *MockShortURLGenerator) NextN(ctx context.Context, n int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NextN", ctx, n)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NextN indicates an expected call of NextN.
+func (mr *MockShortURLGeneratorMockRecorder) NextN(ctx, n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NextN", reflect.TypeOf((*MockShortURLGenerator)(nil).NextN), ctx, n)
+}
+
 // MockCacher is a mock of Cacher interface.
 type MockCacher struct {
 	ctrl     *gomock.Controller
@@ -206,6 +454,20 @@ func (m *MockCacher) EXPECT() *MockCacherMockRecorder {
 	return m.recorder
 }
 
+// Add mocks base method.
+func (m *MockCacher) Add(ctx context.Context, url domain.URL, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, url, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockCacherMockRecorder) Add(ctx, url, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockCacher)(nil).Add), ctx, url, ttl)
+}
+
 // Del mocks base method.
 func (m *MockCacher) Del(ctx context.Context, shortURL string) error {
 	m.ctrl.T.Helper()
@@ -221,10 +483,10 @@ func (mr *MockCacherMockRecorder) Del(ctx, shortURL interface{}) *gomock.Call {
 }
 
 // Get mocks base method.
-func (m *MockCacher) Get(ctx context.Context, shortURL string) (string, error) {
+func (m *MockCacher) Get(ctx context.Context, shortURL string) (domain.URL, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", ctx, shortURL)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(domain.URL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -236,15 +498,383 @@ func (mr *MockCacherMockRecorder) Get(ctx, shortURL interface{}) *gomock.Call {
 }
 
 // Set mocks base method.
-func (m *MockCacher) Set(ctx context.Context, shortURL, longURL string) error {
+func (m *MockCacher) Set(ctx context.Context, url domain.URL, ttl time.Duration) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", ctx, shortURL, longURL)
+	ret := m.ctrl.Call(m, "Set", ctx, url, ttl)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Set indicates an expected call of Set.
-func (mr *MockCacherMockRecorder) Set(ctx, shortURL, longURL interface{}) *gomock.Call {
+func (mr *MockCacherMockRecorder) Set(ctx, url, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCacher)(nil).Set), ctx, url, ttl)
+}
+
+// SetMany mocks base method.
+func (m *MockCacher) SetMany(ctx context.Context, urls []domain.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMany", ctx, urls)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMany indicates an expected call of SetMany.
+func (mr *MockCacherMockRecorder) SetMany(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMany", reflect.TypeOf((*MockCacher)(nil).SetMany), ctx, urls)
+}
+
+// SetNotFound mocks base method.
+func (m *MockCacher) SetNotFound(ctx context.Context, shortURL string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotFound", ctx, shortURL, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotFound indicates an expected call of SetNotFound.
+func (mr *MockCacherMockRecorder) SetNotFound(ctx, shortURL, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotFound", reflect.TypeOf((*MockCacher)(nil).SetNotFound), ctx, shortURL, ttl)
+}
+
+// MockShortURLFilter is a mock of ShortURLFilter interface.
+type MockShortURLFilter struct {
+	ctrl     *gomock.Controller
+	recorder *MockShortURLFilterMockRecorder
+}
+
+// MockShortURLFilterMockRecorder is the mock recorder for MockShortURLFilter.
+type MockShortURLFilterMockRecorder struct {
+	mock *MockShortURLFilter
+}
+
+// NewMockShortURLFilter creates a new mock instance.
+func NewMockShortURLFilter(ctrl *gomock.Controller) *MockShortURLFilter {
+	mock := &MockShortURLFilter{ctrl: ctrl}
+	mock.recorder = &MockShortURLFilterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShortURLFilter) EXPECT() *MockShortURLFilterMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockShortURLFilter) Add(shortURL string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Add", shortURL)
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockShortURLFilterMockRecorder) Add(shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockShortURLFilter)(nil).Add), shortURL)
+}
+
+// MayContain mocks base method.
+func (m *MockShortURLFilter) MayContain(shortURL string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MayContain", shortURL)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// MayContain indicates an expected call of MayContain.
+func (mr *MockShortURLFilterMockRecorder) MayContain(shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MayContain", reflect.TypeOf((*MockShortURLFilter)(nil).MayContain), shortURL)
+}
+
+// MockClickRecorder is a mock of ClickRecorder interface.
+type MockClickRecorder struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickRecorderMockRecorder
+}
+
+// MockClickRecorderMockRecorder is the mock recorder for MockClickRecorder.
+type MockClickRecorderMockRecorder struct {
+	mock *MockClickRecorder
+}
+
+// NewMockClickRecorder creates a new mock instance.
+func NewMockClickRecorder(ctrl *gomock.Controller) *MockClickRecorder {
+	mock := &MockClickRecorder{ctrl: ctrl}
+	mock.recorder = &MockClickRecorderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickRecorder) EXPECT() *MockClickRecorderMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockClickRecorder) Record(ctx context.Context, click domain.Click) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, click)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockClickRecorderMockRecorder) Record(ctx, click interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockClickRecorder)(nil).Record), ctx, click)
+}
+
+// MockClickRepository is a mock of ClickRepository interface.
+type MockClickRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickRepositoryMockRecorder
+}
+
+// MockClickRepositoryMockRecorder is the mock recorder for MockClickRepository.
+type MockClickRepositoryMockRecorder struct {
+	mock *MockClickRepository
+}
+
+// NewMockClickRepository creates a new mock instance.
+func NewMockClickRepository(ctrl *gomock.Controller) *MockClickRepository {
+	mock := &MockClickRepository{ctrl: ctrl}
+	mock.recorder = &MockClickRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickRepository) EXPECT() *MockClickRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method.
+func (m *MockClickRepository) Save(ctx context.Context, clicks []domain.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, clicks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockClickRepositoryMockRecorder) Save(ctx, clicks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockClickRepository)(nil).Save), ctx, clicks)
+}
+
+// Stats mocks base method.
+func (m *MockClickRepository) Stats(ctx context.Context, shortURL string) (domain.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", ctx, shortURL)
+	ret0, _ := ret[0].(domain.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockClickRepositoryMockRecorder) Stats(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockClickRepository)(nil).Stats), ctx, shortURL)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Append mocks base method.
+func (m *MockAuditRepository) Append(ctx context.Context, event domain.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Append", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Append indicates an expected call of Append.
+func (mr *MockAuditRepositoryMockRecorder) Append(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Append", reflect.TypeOf((*MockAuditRepository)(nil).Append), ctx, event)
+}
+
+// List mocks base method.
+func (m *MockAuditRepository) List(ctx context.Context, shortURL string) ([]domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, shortURL)
+	ret0, _ := ret[0].([]domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryMockRecorder) List(ctx, shortURL interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepository)(nil).List), ctx, shortURL)
+}
+
+// MockAuthService is a mock of AuthService interface.
+type MockAuthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthServiceMockRecorder
+}
+
+// MockAuthServiceMockRecorder is the mock recorder for MockAuthService.
+type MockAuthServiceMockRecorder struct {
+	mock *MockAuthService
+}
+
+// NewMockAuthService creates a new mock instance.
+func NewMockAuthService(ctrl *gomock.Controller) *MockAuthService {
+	mock := &MockAuthService{ctrl: ctrl}
+	mock.recorder = &MockAuthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAuthService) Authenticate(ctx context.Context, secret string) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, secret)
+	ret0, _ := ret[0].(domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAuthServiceMockRecorder) Authenticate(ctx, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthService)(nil).Authenticate), ctx, secret)
+}
+
+// CreateKey mocks base method.
+func (m *MockAuthService) CreateKey(ctx context.Context, owner string, admin bool) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, owner, admin)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockAuthServiceMockRecorder) CreateKey(ctx, owner, admin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockAuthService)(nil).CreateKey), ctx, owner, admin)
+}
+
+// MockAPIKeyRepository is a mock of APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepositoryMockRecorder
+}
+
+// MockAPIKeyRepositoryMockRecorder is the mock recorder for MockAPIKeyRepository.
+type MockAPIKeyRepositoryMockRecorder struct {
+	mock *MockAPIKeyRepository
+}
+
+// NewMockAPIKeyRepository creates a new mock instance.
+func NewMockAPIKeyRepository(ctrl *gomock.Controller) *MockAPIKeyRepository {
+	mock := &MockAPIKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepository) EXPECT() *MockAPIKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key domain.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyRepositoryMockRecorder) Create(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyRepository)(nil).Create), ctx, key)
+}
+
+// Find mocks base method.
+func (m *MockAPIKeyRepository) Find(ctx context.Context, hash string) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", ctx, hash)
+	ret0, _ := ret[0].(domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockAPIKeyRepositoryMockRecorder) Find(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockAPIKeyRepository)(nil).Find), ctx, hash)
+}
+
+// MockServiceMetrics is a mock of ServiceMetrics interface.
+type MockServiceMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMetricsMockRecorder
+}
+
+// MockServiceMetricsMockRecorder is the mock recorder for MockServiceMetrics.
+type MockServiceMetricsMockRecorder struct {
+	mock *MockServiceMetrics
+}
+
+// NewMockServiceMetrics creates a new mock instance.
+func NewMockServiceMetrics(ctrl *gomock.Controller) *MockServiceMetrics {
+	mock := &MockServiceMetrics{ctrl: ctrl}
+	mock.recorder = &MockServiceMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceMetrics) EXPECT() *MockServiceMetricsMockRecorder {
+	return m.recorder
+}
+
+// CacheLookup mocks base method.
+func (m *MockServiceMetrics) CacheLookup(hit bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CacheLookup", hit)
+}
+
+// CacheLookup indicates an expected call of CacheLookup.
+func (mr *MockServiceMetricsMockRecorder) CacheLookup(hit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheLookup", reflect.TypeOf((*MockServiceMetrics)(nil).CacheLookup), hit)
+}
+
+// StorageLookup mocks base method.
+func (m *MockServiceMetrics) StorageLookup(coalesced bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StorageLookup", coalesced)
+}
+
+// StorageLookup indicates an expected call of StorageLookup.
+func (mr *MockServiceMetricsMockRecorder) StorageLookup(coalesced interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCacher)(nil).Set), ctx, shortURL, longURL)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageLookup", reflect.TypeOf((*MockServiceMetrics)(nil).StorageLookup), coalesced)
 }
@@ -2,28 +2,85 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/shalimski/shortener/internal/domain"
 )
 
 type ShortenerService interface {
-	Create(ctx context.Context, longURL string) (shortURL string, err error)
-	Find(ctx context.Context, shortURL string) (longURL string, err error)
-	Delete(ctx context.Context, shortURL string) error
+	Create(ctx context.Context, url domain.URL) (shortURL string, err error)
+	CreateWithAlias(ctx context.Context, url domain.URL) error
+	CreateBatch(ctx context.Context, urls []domain.URL) []domain.BatchResult
+	Find(ctx context.Context, shortURL string) (domain.URL, error)
+	Unlock(ctx context.Context, shortURL, password, client string) (domain.URL, error)
+	Update(ctx context.Context, shortURL, longURL string, key domain.APIKey) error
+	Delete(ctx context.Context, shortURL string, key domain.APIKey) error
+	Restore(ctx context.Context, shortURL string, key domain.APIKey) error
+	History(ctx context.Context, shortURL string, key domain.APIKey) ([]domain.AuditEvent, error)
+	Stats(ctx context.Context, shortURL string, key domain.APIKey) (domain.Stats, error)
+	Info(ctx context.Context, shortURL string, key domain.APIKey) (domain.URL, error)
+	List(ctx context.Context, query domain.ListQuery, key domain.APIKey) (domain.ListPage, error)
 }
 
 type Repository interface {
 	Create(ctx context.Context, url domain.URL) error
+	CreateMany(ctx context.Context, urls []domain.URL) []error
 	Find(ctx context.Context, shortURL string) (domain.URL, error)
-	Delete(ctx context.Context, shortURL string) error
+	FindByLongURL(ctx context.Context, owner, longURL string) (domain.URL, error)
+	FindByIdempotencyKey(ctx context.Context, owner, key string) (domain.URL, error)
+	Update(ctx context.Context, url domain.URL) error
+	Delete(ctx context.Context, shortURL, deletedBy string) error
+	Restore(ctx context.Context, shortURL string) error
+	EachShortURL(ctx context.Context, fn func(shortURL string)) error
+	List(ctx context.Context, query domain.ListQuery) ([]domain.URL, error)
 }
 
 type ShortURLGenerator interface {
 	Next(ctx context.Context) (string, error)
+	NextN(ctx context.Context, n int) ([]string, error)
 }
 
 type Cacher interface {
-	Set(ctx context.Context, shortURL string, longURL string) (err error)
-	Get(ctx context.Context, shortURL string) (longURL string, err error)
+	Set(ctx context.Context, url domain.URL, ttl time.Duration) (err error)
+	Add(ctx context.Context, url domain.URL, ttl time.Duration) (err error)
+	SetMany(ctx context.Context, urls []domain.URL) (err error)
+	SetNotFound(ctx context.Context, shortURL string, ttl time.Duration) (err error)
+	Get(ctx context.Context, shortURL string) (url domain.URL, err error)
 	Del(ctx context.Context, shortURL string) (err error)
 }
+
+// ShortURLFilter is a set of existing short urls which never reports existing short url as missing
+type ShortURLFilter interface {
+	Add(shortURL string)
+	MayContain(shortURL string) bool
+}
+
+type ClickRecorder interface {
+	Record(ctx context.Context, click domain.Click)
+}
+
+type ClickRepository interface {
+	Save(ctx context.Context, clicks []domain.Click) error
+	Stats(ctx context.Context, shortURL string) (domain.Stats, error)
+}
+
+// AuditRepository is append-only log of url changes
+type AuditRepository interface {
+	Append(ctx context.Context, event domain.AuditEvent) error
+	List(ctx context.Context, shortURL string) ([]domain.AuditEvent, error)
+}
+
+type AuthService interface {
+	Authenticate(ctx context.Context, secret string) (domain.APIKey, error)
+	CreateKey(ctx context.Context, owner string, admin bool) (secret string, err error)
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key domain.APIKey) error
+	Find(ctx context.Context, hash string) (domain.APIKey, error)
+}
+
+type ServiceMetrics interface {
+	CacheLookup(hit bool)
+	StorageLookup(coalesced bool)
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const secretLength = 32
+
+var _ ports.AuthService = (*authService)(nil)
+
+type authService struct {
+	log  *logger.Logger
+	keys ports.APIKeyRepository
+}
+
+// NewAuthService create instance of service managing api keys
+func NewAuthService(log *logger.Logger, keys ports.APIKeyRepository) ports.AuthService {
+	return authService{
+		log:  log,
+		keys: keys,
+	}
+}
+
+// Authenticate finds api key by its secret
+func (s authService) Authenticate(ctx context.Context, secret string) (domain.APIKey, error) {
+	if secret == "" {
+		return domain.APIKey{}, domain.ErrUnauthorized
+	}
+
+	key, err := s.keys.Find(ctx, HashSecret(secret))
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.APIKey{}, domain.ErrUnauthorized
+	}
+
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to find api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// CreateKey generate new api key for owner, secret is returned only once and stored as hash
+func (s authService) CreateKey(ctx context.Context, owner string, admin bool) (string, error) {
+	s.log.Debug(ctx, "start CreateKey method", zap.String("owner", owner), zap.Bool("admin", admin))
+
+	buf := make([]byte, secretLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+
+	key := domain.APIKey{
+		Hash:      HashSecret(secret),
+		Owner:     owner,
+		Admin:     admin,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.keys.Create(ctx, key); err != nil {
+		return "", fmt.Errorf("failed to save api key: %w", err)
+	}
+
+	return secret, nil
+}
+
+// HashSecret returns hex encoded sha256 of api key secret,
+// secrets are random and long enough, so slow password hashes are not needed
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,51 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shalimski/shortener/internal/domain"
+	mock "github.com/shalimski/shortener/internal/ports/mock"
+	"github.com/shalimski/shortener/internal/services"
+	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	var stored domain.APIKey
+
+	keys := mock.NewMockAPIKeyRepository(ctl)
+	keys.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, key domain.APIKey) error {
+		stored = key
+
+		return nil
+	})
+
+	auth := services.NewAuthService(log, keys)
+
+	secret, err := auth.CreateKey(ctx, "marketing", false)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, secret)
+	assert.NotEqual(t, secret, stored.Hash)
+	assert.Equal(t, services.HashSecret(secret), stored.Hash)
+
+	keys.EXPECT().Find(ctx, stored.Hash).Return(stored, nil)
+	keys.EXPECT().Find(ctx, services.HashSecret("wrong")).Return(domain.APIKey{}, domain.ErrNotFound)
+
+	key, err := auth.Authenticate(ctx, secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "marketing", key.Owner)
+
+	_, err = auth.Authenticate(ctx, "wrong")
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+
+	_, err = auth.Authenticate(ctx, "")
+	assert.ErrorIs(t, err, domain.ErrUnauthorized)
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/ratelimit"
+)
+
+type Option func(*service)
+
+// Dedup enables returning existing short url for already shortened long url
+func Dedup(enabled bool) Option {
+	return func(s *service) {
+		s.dedup = enabled
+	}
+}
+
+// Metrics sets collector of service metrics
+func Metrics(m ports.ServiceMetrics) Option {
+	return func(s *service) {
+		s.metrics = m
+	}
+}
+
+// NotFoundTTL enables caching of missing short urls for ttl
+func NotFoundTTL(ttl time.Duration) Option {
+	return func(s *service) {
+		s.notFoundTTL = ttl
+	}
+}
+
+// Filter sets set of existing short urls, short urls missing in filter are not looked up in cache and storage.
+// Filter must contain every stored short url and be updated by every created short url
+func Filter(f ports.ShortURLFilter) Option {
+	return func(s *service) {
+		s.filter = f
+	}
+}
+
+// CreateAttempts sets how many generated short urls are tried when they are already taken,
+// random generators need more attempts as storage fills up
+func CreateAttempts(n int) Option {
+	return func(s *service) {
+		if n > 0 {
+			s.createAttempts = n
+		}
+	}
+}
+
+// Audit sets append-only log of url changes
+func Audit(repo ports.AuditRepository) Option {
+	return func(s *service) {
+		s.audit = repo
+	}
+}
+
+// PasswordAttempts sets counter of wrong passwords of protected urls, it is keyed by short url and client address.
+// Shared counter such as ratelimit.RedisAttempts limits attempts across nodes
+func PasswordAttempts(attempts ratelimit.Attempts) Option {
+	return func(s *service) {
+		s.attempts = attempts
+	}
+}
+
+// noopMetrics is used when service metrics are not collected
+type noopMetrics struct{}
+
+func (noopMetrics) CacheLookup(bool) {}
+
+func (noopMetrics) StorageLookup(bool) {}
+
+// noopAudit is used when url changes are not recorded
+type noopAudit struct{}
+
+func (noopAudit) Append(context.Context, domain.AuditEvent) error { return nil }
+
+func (noopAudit) List(context.Context, string) ([]domain.AuditEvent, error) { return nil, nil }
@@ -4,92 +4,674 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/shalimski/shortener/internal/domain"
 	"github.com/shalimski/shortener/internal/ports"
 	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/shalimski/shortener/pkg/password"
+	"github.com/shalimski/shortener/pkg/ratelimit"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 var _ ports.ShortenerService = (*service)(nil)
 
 type service struct {
-	log    *logger.Logger
-	repo   ports.Repository
-	urlgen ports.ShortURLGenerator
-	cache  ports.Cacher
+	log     *logger.Logger
+	repo    ports.Repository
+	urlgen  ports.ShortURLGenerator
+	cache   ports.Cacher
+	clicks  ports.ClickRepository
+	dedup   bool // return existing short url for already shortened long url
+	metrics ports.ServiceMetrics
+
+	notFoundTTL time.Duration        // lifetime of cached missing short url, zero disables caching
+	filter      ports.ShortURLFilter // optional set of existing short urls
+
+	lookups *singleflight.Group // collapses concurrent storage lookups of the same short url
+
+	createAttempts int // how many generated short urls are tried when they are already taken
+
+	audit ports.AuditRepository // append-only log of url changes
+
+	attempts ratelimit.Attempts // wrong passwords of protected urls by short url and client
 }
 
 // NewService create instance of core service, it incapsulate all business logic
-func NewService(log *logger.Logger, repo ports.Repository, urlgen ports.ShortURLGenerator, cache ports.Cacher) ports.ShortenerService {
-	return service{
-		log:    log,
-		repo:   repo,
-		urlgen: urlgen,
-		cache:  cache,
+func NewService(log *logger.Logger, repo ports.Repository, urlgen ports.ShortURLGenerator, cache ports.Cacher, clicks ports.ClickRepository, opts ...Option) ports.ShortenerService {
+	s := service{
+		log:     log,
+		repo:    repo,
+		urlgen:  urlgen,
+		cache:   cache,
+		clicks:  clicks,
+		metrics: noopMetrics{},
+		lookups: &singleflight.Group{},
+
+		createAttempts: defaultCreateAttempts,
+		audit:          noopAudit{},
+		attempts:       ratelimit.NewMemoryAttempts(defaultPasswordAttempts, defaultPasswordWindow),
+	}
+
+	for _, opt := range opts {
+		opt(&s)
 	}
+
+	return s
 }
 
+// defaultPageSize is number of listed urls when query has no limit
+const defaultPageSize = 50
+
+// defaultCreateAttempts limits how many generated short urls are tried when they are already taken by aliases
+// or by colliding random short urls
+const defaultCreateAttempts = 5
+
+// default limit of wrong passwords of protected url tried by one client
+const (
+	defaultPasswordAttempts = 5
+	defaultPasswordWindow   = 15 * time.Minute
+)
+
 // Create generate new short url for long url and save it to storage and cache
-func (s service) Create(ctx context.Context, longURL string) (string, error) {
-	s.log.Debug(ctx, "start Create method", zap.String("longURL", longURL))
+func (s service) Create(ctx context.Context, url domain.URL) (string, error) {
+	s.log.Debug(ctx, "start Create method", zap.String("longURL", url.LongURL))
 
-	shortURL, err := s.urlgen.Next(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to get next short url: %w", err)
+	existing, err := s.findExisting(ctx, url)
+	if err == nil {
+		s.log.Debug(ctx, "url already shortened", zap.String("shortURL", existing.ShortURL))
+
+		return existing.ShortURL, nil
+	}
+
+	if !errors.Is(err, domain.ErrNotFound) {
+		return "", err
+	}
+
+	for attempt := 0; attempt < s.createAttempts; attempt++ {
+		shortURL, err := s.urlgen.Next(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get next short url: %w", err)
+		}
+
+		s.log.Debug(ctx, "generated url", zap.String("shortURL", shortURL))
+
+		if domain.IsReservedShortURL(shortURL) {
+			continue
+		}
+
+		url.ShortURL = shortURL
+
+		err = s.save(ctx, url)
+		if errors.Is(err, domain.ErrAlreadyExists) && url.IdempotencyKey != "" {
+			// concurrent request with the same idempotency key could be first
+			found, ferr := s.findExisting(ctx, url)
+			if ferr == nil {
+				return found.ShortURL, nil
+			}
+
+			if !errors.Is(ferr, domain.ErrNotFound) {
+				return "", ferr
+			}
+		}
+
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			// generated value is already taken by a custom alias or another random short url, skip it
+			s.log.Info(ctx, "generated url already exists", zap.String("shortURL", shortURL))
+
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		s.record(ctx, url, domain.AuditCreated, url.Owner)
+
+		return shortURL, nil
 	}
 
-	s.log.Debug(ctx, "generated url", zap.String("shortURL", shortURL))
+	s.log.Error(ctx, "failed to create url, attempts exhausted", zap.Int("attempts", s.createAttempts))
 
-	url := domain.URL{
-		ShortURL: shortURL,
-		LongURL:  longURL,
+	return "", domain.ErrFailedToCreate
+}
+
+// CreateBatch generate short urls for batch of long urls, every long url is reported separately
+func (s service) CreateBatch(ctx context.Context, urls []domain.URL) []domain.BatchResult {
+	s.log.Debug(ctx, "start CreateBatch method", zap.Int("count", len(urls)))
+
+	results := make([]domain.BatchResult, len(urls))
+	pending := make([]int, 0, len(urls)) // indexes of urls waiting for short url
+
+	for i, url := range urls {
+		results[i].LongURL = url.LongURL
+
+		existing, err := s.findExisting(ctx, url)
+
+		switch {
+		case err == nil:
+			results[i].ShortURL = existing.ShortURL
+		case errors.Is(err, domain.ErrNotFound):
+			pending = append(pending, i)
+		default:
+			results[i].Err = err
+		}
+	}
+
+	for attempt := 0; attempt < s.createAttempts && len(pending) > 0; attempt++ {
+		shortURLs, err := s.urlgen.NextN(ctx, len(pending))
+		if err != nil {
+			s.log.Error(ctx, "failed to get next short urls", zap.Error(err))
+
+			break
+		}
+
+		batch := make([]domain.URL, 0, len(pending))
+		batched := make([]int, 0, len(pending)) // indexes of urls in batch
+		retry := make([]int, 0)
+
+		for i, idx := range pending {
+			if domain.IsReservedShortURL(shortURLs[i]) {
+				retry = append(retry, idx)
+
+				continue
+			}
+
+			url := urls[idx]
+			url.ShortURL = shortURLs[i]
+			s.addToFilter(url.ShortURL)
+
+			batch = append(batch, url)
+			batched = append(batched, idx)
+		}
+
+		if len(batch) == 0 {
+			pending = retry
+
+			continue
+		}
+
+		errs := s.repo.CreateMany(ctx, batch)
+
+		created := make([]domain.URL, 0, len(batch))
+
+		for i, idx := range batched {
+			switch {
+			case errs[i] == nil:
+				results[idx].ShortURL = batch[i].ShortURL
+				created = append(created, batch[i])
+				s.record(ctx, batch[i], domain.AuditCreated, batch[i].Owner)
+			case errors.Is(errs[i], domain.ErrAlreadyExists):
+				// generated value is already taken, retry with another one
+				retry = append(retry, idx)
+			default:
+				s.log.Error(ctx, "failed to create url", zap.Error(errs[i]))
+				results[idx].Err = domain.ErrFailedToCreate
+			}
+		}
+
+		if len(created) != 0 {
+			if err := s.cache.SetMany(ctx, created); err != nil {
+				s.log.Error(ctx, "failed to set in cache", zap.Error(err))
+			}
+		}
+
+		pending = retry
+	}
+
+	for _, idx := range pending {
+		results[idx].Err = domain.ErrFailedToCreate
 	}
 
+	return results
+}
+
+// findExisting looks for already shortened url by idempotency key or by long url of plain url in dedup mode
+func (s service) findExisting(ctx context.Context, url domain.URL) (domain.URL, error) {
+	var (
+		existing domain.URL
+		err      error
+	)
+
+	switch {
+	case url.IdempotencyKey != "":
+		existing, err = s.repo.FindByIdempotencyKey(ctx, url.Owner, url.IdempotencyKey)
+		if err == nil && existing.LongURL != url.LongURL {
+			return domain.URL{}, domain.ErrKeyReused
+		}
+	case s.dedup && isPlain(url):
+		existing, err = s.repo.FindByLongURL(ctx, url.Owner, url.LongURL)
+		if err == nil && !isPlain(existing) {
+			err = domain.ErrNotFound
+		}
+	default:
+		return domain.URL{}, domain.ErrNotFound
+	}
+
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		s.log.Error(ctx, "failed to find existing url", zap.Error(err))
+
+		return domain.URL{}, domain.ErrFailedToCreate
+	}
+
+	return existing, err
+}
+
+// isPlain reports whether url has no password, expiration and own redirect code, only such urls are deduplicated,
+// so request asking for protection never gets public link and the other way round
+func isPlain(url domain.URL) bool {
+	return url.PasswordHash == "" && url.ExpiresAt == nil && url.RedirectCode == 0
+}
+
+// CreateWithAlias save long url under user defined short url
+func (s service) CreateWithAlias(ctx context.Context, url domain.URL) error {
+	s.log.Debug(ctx, "start CreateWithAlias method", zap.String("alias", url.ShortURL), zap.String("longURL", url.LongURL))
+
+	if err := s.save(ctx, url); err != nil {
+		return err
+	}
+
+	s.record(ctx, url, domain.AuditCreated, url.Owner)
+
+	return nil
+}
+
+// save url to storage and cache
+func (s service) save(ctx context.Context, url domain.URL) error {
+	s.addToFilter(url.ShortURL)
+
 	if err := s.repo.Create(ctx, url); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return err
+		}
+
 		s.log.Error(ctx, "failed to create url", zap.Error(err))
 
-		return "", domain.ErrFailedToCreate
+		return domain.ErrFailedToCreate
+	}
+
+	s.setCache(ctx, url)
+
+	return nil
+}
+
+// setCache puts url to cache, cache entry lives until url expiration
+func (s service) setCache(ctx context.Context, url domain.URL) {
+	ttl, ok := url.TTL(time.Now())
+	if !ok {
+		return
 	}
 
-	if err := s.cache.Set(ctx, shortURL, longURL); err != nil {
+	if err := s.cache.Set(ctx, url, ttl); err != nil {
 		s.log.Error(ctx, "failed to set in cache", zap.Error(err))
 	}
+}
+
+// fillCache puts url read from storage to cache only if cache has no entry,
+// so concurrent update written to cache is never overwritten by stale value
+func (s service) fillCache(ctx context.Context, url domain.URL) {
+	ttl, ok := url.TTL(time.Now())
+	if !ok {
+		return
+	}
 
-	return shortURL, nil
+	if err := s.cache.Add(ctx, url, ttl); err != nil {
+		s.log.Error(ctx, "failed to add in cache", zap.Error(err))
+	}
 }
 
-// Find gets the long link from the cache or storage
-func (s service) Find(ctx context.Context, shortURL string) (longURL string, err error) {
+// addToFilter adds short url to filter before it is saved, so filter never misses saved short url
+func (s service) addToFilter(shortURL string) {
+	if s.filter != nil {
+		s.filter.Add(shortURL)
+	}
+}
+
+// record appends change of url to audit log, failed record does not fail the change
+func (s service) record(ctx context.Context, url domain.URL, action domain.AuditAction, actor string) {
+	if err := s.audit.Append(ctx, domain.NewAuditEvent(url, action, actor, time.Now())); err != nil {
+		s.log.Error(ctx, "failed to append audit event", zap.String("action", string(action)), zap.Error(err))
+	}
+}
+
+// rewriteCache replaces cached url on every node, entry is dropped if it can not be replaced
+func (s service) rewriteCache(ctx context.Context, url domain.URL) error {
+	ttl, _ := url.TTL(time.Now())
+	if err := s.cache.Set(ctx, url, ttl); err != nil {
+		s.log.Error(ctx, "failed to set in cache", zap.Error(err))
+
+		if err := s.cache.Del(ctx, url.ShortURL); err != nil {
+			return fmt.Errorf("failed to invalidate cache: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cacheNotFound remembers missing short url, so probing of missing short urls does not reach storage,
+// creation of short url rewrites cache entry
+func (s service) cacheNotFound(ctx context.Context, shortURL string) {
+	if s.notFoundTTL <= 0 {
+		return
+	}
+
+	if err := s.cache.SetNotFound(ctx, shortURL, s.notFoundTTL); err != nil {
+		s.log.Error(ctx, "failed to set not found in cache", zap.Error(err))
+	}
+}
+
+// Find gets the link from the cache or storage
+func (s service) Find(ctx context.Context, shortURL string) (domain.URL, error) {
 	s.log.Debug(ctx, "start Find method", zap.String("shortURL", shortURL))
 
-	if longURL, err = s.cache.Get(ctx, shortURL); err == nil {
-		return longURL, nil
+	if s.filter != nil && !s.filter.MayContain(shortURL) {
+		return domain.URL{}, domain.ErrNotFound
 	}
 
-	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+	url, err := s.cache.Get(ctx, shortURL)
+	if err == nil {
+		s.metrics.CacheLookup(true)
+
+		if url.IsDeleted() {
+			return domain.URL{}, domain.ErrDeleted
+		}
+
+		return url, nil
+	}
+
+	if errors.Is(err, domain.ErrCachedNotFound) {
+		s.metrics.CacheLookup(true)
+
+		return domain.URL{}, domain.ErrNotFound
+	}
+
+	s.metrics.CacheLookup(false)
+
+	if !errors.Is(err, domain.ErrNotFound) {
 		s.log.Error(ctx, "failed to get in cache", zap.Error(err))
 	}
 
+	return s.lookup(ctx, shortURL)
+}
+
+// lookup finds url in storage, concurrent lookups of the same short url share one storage call
+func (s service) lookup(ctx context.Context, shortURL string) (domain.URL, error) {
+	executed := false
+
+	ch := s.lookups.DoChan(shortURL, func() (any, error) {
+		executed = true
+
+		return s.load(ctx, shortURL)
+	})
+
+	var res singleflight.Result
+
+	select {
+	case <-ctx.Done():
+		return domain.URL{}, ctx.Err()
+	case res = <-ch:
+	}
+
+	s.metrics.StorageLookup(!executed)
+
+	// shared lookup is canceled with context of request started it, other requests retry on their own
+	if !executed && ctx.Err() == nil && (errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) {
+		return s.load(ctx, shortURL)
+	}
+
+	if res.Err != nil {
+		return domain.URL{}, res.Err
+	}
+
+	return res.Val.(domain.URL), nil //nolint:forcetypeassert // load returns url
+}
+
+// load finds url in storage and puts result to cache
+func (s service) load(ctx context.Context, shortURL string) (domain.URL, error) {
 	url, err := s.repo.Find(ctx, shortURL)
+	if errors.Is(err, domain.ErrNotFound) {
+		s.cacheNotFound(ctx, shortURL)
+	}
+
 	if err != nil {
-		return "", err
+		return domain.URL{}, err
 	}
 
-	if err = s.cache.Set(ctx, url.ShortURL, url.LongURL); err != nil {
-		s.log.Error(ctx, "failed to get in cache", zap.Error(err))
+	// storage removes expired urls with a delay
+	if url.IsExpired(time.Now()) {
+		return domain.URL{}, domain.ErrExpired
+	}
+
+	// tombstone is cached too, so lookups of deleted url do not reach storage
+	s.fillCache(ctx, url)
+
+	if url.IsDeleted() {
+		return domain.URL{}, domain.ErrDeleted
+	}
+
+	return url, nil
+}
+
+// Unlock finds protected url and returns it when password matches,
+// wrong passwords are limited by short url and client, public urls are returned without check
+func (s service) Unlock(ctx context.Context, shortURL, pass, client string) (domain.URL, error) {
+	s.log.Debug(ctx, "start Unlock method", zap.String("shortURL", shortURL), zap.String("client", client))
+
+	url, err := s.Find(ctx, shortURL)
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	if !url.IsProtected() {
+		return url, nil
+	}
+
+	key := shortURL + "/" + client
+
+	// attempt is reserved before comparison, so concurrent guesses can not exceed the limit
+	ok, err := s.attempts.Take(ctx, key)
+	if err != nil {
+		s.log.Error(ctx, "failed to count password attempt", zap.String("shortURL", shortURL), zap.Error(err))
+
+		return domain.URL{}, err
+	}
+
+	if !ok {
+		s.log.Info(ctx, "too many wrong passwords", zap.String("shortURL", shortURL), zap.String("client", client))
+
+		return domain.URL{}, domain.ErrTooManyAttempts
+	}
+
+	if !password.Verify(url.PasswordHash, pass) {
+		return domain.URL{}, domain.ErrWrongPassword
+	}
+
+	if err := s.attempts.Forgive(ctx, key); err != nil {
+		s.log.Error(ctx, "failed to forgive password attempt", zap.String("shortURL", shortURL), zap.Error(err))
+	}
+
+	return url, nil
+}
+
+// Update long url of short url in storage and rewrite cache, only owner of short url can update it
+func (s service) Update(ctx context.Context, shortURL, longURL string, key domain.APIKey) error {
+	s.log.Debug(ctx, "start Update method", zap.String("shortURL", shortURL), zap.String("longURL", longURL))
+
+	url, err := s.repo.Find(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if !key.CanManage(url) {
+		return domain.ErrForbidden
+	}
+
+	if url.IsDeleted() {
+		return domain.ErrDeleted
+	}
+
+	if url.IsExpired(time.Now()) {
+		return domain.ErrExpired
+	}
+
+	url.LongURL = longURL
+
+	if err := s.repo.Update(ctx, url); err != nil {
+		return err
+	}
+
+	s.record(ctx, url, domain.AuditUpdated, key.Owner)
+
+	// cache is shared by all nodes, so rewriting it switches every node to new long url
+	return s.rewriteCache(ctx, url)
+}
+
+// Delete marks short url as deleted in storage and cache, only owner of short url can delete it
+func (s service) Delete(ctx context.Context, shortURL string, key domain.APIKey) error {
+	s.log.Debug(ctx, "start Delete method", zap.String("shortURL", shortURL), zap.String("owner", key.Owner))
+
+	url, err := s.repo.Find(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if !key.CanManage(url) {
+		return domain.ErrForbidden
+	}
+
+	if url.IsDeleted() {
+		return domain.ErrDeleted
+	}
+
+	if err := s.repo.Delete(ctx, shortURL, key.Owner); err != nil {
+		return err
+	}
+
+	s.record(ctx, url, domain.AuditDeleted, key.Owner)
+
+	now := time.Now().UTC()
+	url.DeletedAt = &now
+	url.DeletedBy = key.Owner
+
+	return s.rewriteCache(ctx, url)
+}
+
+// Restore removes tombstone of deleted short url, only owner of short url can restore it
+func (s service) Restore(ctx context.Context, shortURL string, key domain.APIKey) error {
+	s.log.Debug(ctx, "start Restore method", zap.String("shortURL", shortURL), zap.String("owner", key.Owner))
+
+	url, err := s.repo.Find(ctx, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if !key.CanManage(url) {
+		return domain.ErrForbidden
+	}
+
+	if !url.IsDeleted() {
+		return domain.ErrNotDeleted
+	}
+
+	if err := s.repo.Restore(ctx, shortURL); err != nil {
+		return err
 	}
 
-	return url.LongURL, nil
+	s.record(ctx, url, domain.AuditRestored, key.Owner)
+
+	url.DeletedAt = nil
+	url.DeletedBy = ""
+
+	// cached tombstone and cached missing short url are replaced on every node
+	return s.rewriteCache(ctx, url)
 }
 
-// Delete short url from cache and storage
-func (s service) Delete(ctx context.Context, shortURL string) error {
-	s.log.Debug(ctx, "start Delete method", zap.String("shortURL", shortURL))
+// History returns audit log of short url, only owner of short url can read it
+func (s service) History(ctx context.Context, shortURL string, key domain.APIKey) ([]domain.AuditEvent, error) {
+	s.log.Debug(ctx, "start History method", zap.String("shortURL", shortURL))
+
+	url, err := s.repo.Find(ctx, shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !key.CanManage(url) {
+		return nil, domain.ErrForbidden
+	}
+
+	return s.audit.List(ctx, shortURL)
+}
+
+// Stats returns aggregated clicks of existing short url, only owner of short url can read them
+func (s service) Stats(ctx context.Context, shortURL string, key domain.APIKey) (domain.Stats, error) {
+	s.log.Debug(ctx, "start Stats method", zap.String("shortURL", shortURL))
+
+	url, err := s.repo.Find(ctx, shortURL)
+	if err != nil {
+		return domain.Stats{}, err
+	}
+
+	if !key.CanManage(url) {
+		return domain.Stats{}, domain.ErrForbidden
+	}
+
+	return s.clicks.Stats(ctx, shortURL)
+}
+
+// Info returns stored url with metadata, expired url is returned too until storage removes it,
+// only owner of short url can read it
+func (s service) Info(ctx context.Context, shortURL string, key domain.APIKey) (domain.URL, error) {
+	s.log.Debug(ctx, "start Info method", zap.String("shortURL", shortURL))
+
+	// cached urls have no timestamps, they are set by storage
+	url, err := s.repo.Find(ctx, shortURL)
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	if !key.CanManage(url) {
+		return domain.URL{}, domain.ErrForbidden
+	}
+
+	return url, nil
+}
+
+// List page of urls selected by query, keys of non admins list only own urls
+func (s service) List(ctx context.Context, query domain.ListQuery, key domain.APIKey) (domain.ListPage, error) {
+	s.log.Debug(ctx, "start List method", zap.String("owner", query.Filter.Owner), zap.String("sort", string(query.Sort)))
+
+	if !key.Admin {
+		if query.Filter.Owner != "" && query.Filter.Owner != key.Owner {
+			return domain.ListPage{}, domain.ErrForbidden
+		}
+
+		query.Filter.Owner = key.Owner
+	}
+
+	if query.After != nil && query.After.Sort != query.Sort {
+		return domain.ListPage{}, domain.ErrInvalidCursor
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+
+	// one more url tells whether next page exists
+	limit := query.Limit
+	query.Limit++
+
+	urls, err := s.repo.List(ctx, query)
+	if err != nil {
+		return domain.ListPage{}, err
+	}
+
+	page := domain.ListPage{URLs: urls}
 
-	if err := s.cache.Del(ctx, shortURL); err != nil {
-		s.log.Error(ctx, "failed to del in cache", zap.Error(err))
+	if len(urls) > limit {
+		page.URLs = urls[:limit]
+		page.NextCursor = domain.NewListCursor(query.Sort, urls[limit-1]).Encode()
 	}
 
-	return s.repo.Delete(ctx, shortURL)
+	return page, nil
 }
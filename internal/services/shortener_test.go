@@ -2,13 +2,20 @@ package services_test
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/shalimski/shortener/internal/domain"
 	mock "github.com/shalimski/shortener/internal/ports/mock"
 	"github.com/shalimski/shortener/internal/services"
+	"github.com/shalimski/shortener/pkg/bloom"
 	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/shalimski/shortener/pkg/password"
+	"github.com/shalimski/shortener/pkg/ratelimit"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,15 +37,279 @@ func TestCreate(t *testing.T) {
 	urlgen.EXPECT().Next(ctx).Return(url.ShortURL, nil)
 
 	cache := mock.NewMockCacher(ctl)
-	cache.EXPECT().Set(ctx, url.ShortURL, url.LongURL).Return(nil)
+	cache.EXPECT().Set(ctx, url, time.Duration(0)).Return(nil)
 
-	service := services.NewService(log, repo, urlgen, cache)
-	shortURL, err := service.Create(ctx, url.LongURL)
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+	shortURL, err := service.Create(ctx, domain.URL{LongURL: url.LongURL})
 
 	assert.NoError(t, err)
 	assert.Equal(t, url.ShortURL, shortURL)
 }
 
+func TestCreateSkipsTakenShortURL(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	taken := domain.URL{
+		ShortURL: "abcd",
+		LongURL:  "http://github.com",
+	}
+	url := domain.URL{
+		ShortURL: "abce",
+		LongURL:  "http://github.com",
+	}
+	repo := mock.NewMockRepository(ctl)
+	gomock.InOrder(
+		repo.EXPECT().Create(ctx, taken).Return(domain.ErrAlreadyExists),
+		repo.EXPECT().Create(ctx, url).Return(nil),
+	)
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+	gomock.InOrder(
+		urlgen.EXPECT().Next(ctx).Return(taken.ShortURL, nil),
+		urlgen.EXPECT().Next(ctx).Return(url.ShortURL, nil),
+	)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, url, time.Duration(0)).Return(nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+	shortURL, err := service.Create(ctx, domain.URL{LongURL: url.LongURL})
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.ShortURL, shortURL)
+}
+
+func TestCreateAttemptsExhausted(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Create(ctx, gomock.Any()).Return(domain.ErrAlreadyExists).Times(3)
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+	urlgen.EXPECT().Next(ctx).Return("abcd", nil).Times(3)
+
+	cache := mock.NewMockCacher(ctl)
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.CreateAttempts(3))
+	_, err := service.Create(ctx, domain.URL{LongURL: "http://github.com"})
+
+	assert.ErrorIs(t, err, domain.ErrFailedToCreate)
+}
+
+func TestCreateDedup(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	url := domain.URL{
+		ShortURL: "abcd",
+		LongURL:  "http://github.com",
+	}
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().FindByLongURL(ctx, url.Owner, url.LongURL).Return(url, nil)
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	cache := mock.NewMockCacher(ctl)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.Dedup(true))
+	shortURL, err := service.Create(ctx, domain.URL{LongURL: url.LongURL})
+
+	assert.NoError(t, err)
+	assert.Equal(t, url.ShortURL, shortURL)
+}
+
+func TestCreateDedupProtected(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	public := domain.URL{
+		ShortURL: "abcd",
+		LongURL:  "http://github.com",
+	}
+	protected := domain.URL{
+		ShortURL:     "efgh",
+		LongURL:      public.LongURL,
+		PasswordHash: "hash",
+	}
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().FindByLongURL(ctx, public.Owner, public.LongURL).Return(protected, nil)
+	repo.EXPECT().Create(ctx, protected).Return(nil)
+	repo.EXPECT().Create(ctx, public).Return(nil)
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+	gomock.InOrder(
+		urlgen.EXPECT().Next(ctx).Return(protected.ShortURL, nil),
+		urlgen.EXPECT().Next(ctx).Return(public.ShortURL, nil),
+	)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, protected, time.Duration(0)).Return(nil)
+	cache.EXPECT().Set(ctx, public, time.Duration(0)).Return(nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.Dedup(true))
+
+	// existing public link is not returned to request with password
+	shortURL, err := service.Create(ctx, domain.URL{LongURL: public.LongURL, PasswordHash: "hash"})
+	assert.NoError(t, err)
+	assert.Equal(t, protected.ShortURL, shortURL)
+
+	// protected link is not returned to request without password
+	shortURL, err = service.Create(ctx, domain.URL{LongURL: public.LongURL})
+	assert.NoError(t, err)
+	assert.Equal(t, public.ShortURL, shortURL)
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	url := domain.URL{
+		ShortURL:       "abcd",
+		LongURL:        "http://github.com",
+		IdempotencyKey: "key",
+	}
+	repo := mock.NewMockRepository(ctl)
+	gomock.InOrder(
+		repo.EXPECT().FindByIdempotencyKey(ctx, url.Owner, url.IdempotencyKey).Return(domain.URL{}, domain.ErrNotFound),
+		repo.EXPECT().Create(ctx, url).Return(nil),
+		repo.EXPECT().FindByIdempotencyKey(ctx, url.Owner, url.IdempotencyKey).Return(url, nil),
+		repo.EXPECT().FindByIdempotencyKey(ctx, url.Owner, url.IdempotencyKey).Return(url, nil),
+	)
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+	urlgen.EXPECT().Next(ctx).Return(url.ShortURL, nil)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, url, time.Duration(0)).Return(nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+
+	// first request creates url
+	shortURL, err := service.Create(ctx, domain.URL{LongURL: url.LongURL, IdempotencyKey: url.IdempotencyKey})
+	assert.NoError(t, err)
+	assert.Equal(t, url.ShortURL, shortURL)
+
+	// retry returns the same url
+	shortURL, err = service.Create(ctx, domain.URL{LongURL: url.LongURL, IdempotencyKey: url.IdempotencyKey})
+	assert.NoError(t, err)
+	assert.Equal(t, url.ShortURL, shortURL)
+
+	// the same key with another long url
+	_, err = service.Create(ctx, domain.URL{LongURL: "http://gitlab.com", IdempotencyKey: url.IdempotencyKey})
+	assert.ErrorIs(t, err, domain.ErrKeyReused)
+}
+
+func TestCreateWithAlias(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	url := domain.URL{
+		ShortURL: "launch2026",
+		LongURL:  "http://github.com",
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	gomock.InOrder(
+		repo.EXPECT().Create(ctx, url).Return(nil),
+		repo.EXPECT().Create(ctx, url).Return(domain.ErrAlreadyExists),
+	)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, url, time.Duration(0)).Return(nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+
+	err := service.CreateWithAlias(ctx, url)
+	assert.NoError(t, err)
+
+	err = service.CreateWithAlias(ctx, url)
+	assert.ErrorIs(t, err, domain.ErrAlreadyExists)
+}
+
+func TestCreateBatch(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	longURLs := []string{"http://github.com", "http://gitlab.com", "http://bitbucket.org"}
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+	gomock.InOrder(
+		urlgen.EXPECT().NextN(ctx, 3).Return([]string{"b", "c", "d"}, nil),
+		urlgen.EXPECT().NextN(ctx, 1).Return([]string{"e"}, nil),
+	)
+
+	repo := mock.NewMockRepository(ctl)
+	gomock.InOrder(
+		repo.EXPECT().CreateMany(ctx, []domain.URL{
+			{ShortURL: "b", LongURL: longURLs[0]},
+			{ShortURL: "c", LongURL: longURLs[1]},
+			{ShortURL: "d", LongURL: longURLs[2]},
+		}).Return([]error{nil, domain.ErrAlreadyExists, errors.New("write error")}),
+		repo.EXPECT().CreateMany(ctx, []domain.URL{
+			{ShortURL: "e", LongURL: longURLs[1]},
+		}).Return([]error{nil}),
+	)
+
+	cache := mock.NewMockCacher(ctl)
+	gomock.InOrder(
+		cache.EXPECT().SetMany(ctx, []domain.URL{{ShortURL: "b", LongURL: longURLs[0]}}).Return(nil),
+		cache.EXPECT().SetMany(ctx, []domain.URL{{ShortURL: "e", LongURL: longURLs[1]}}).Return(nil),
+	)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+	results := service.CreateBatch(ctx, []domain.URL{
+		{LongURL: longURLs[0]},
+		{LongURL: longURLs[1]},
+		{LongURL: longURLs[2]},
+	})
+
+	assert.Equal(t, []domain.BatchResult{
+		{LongURL: longURLs[0], ShortURL: "b"},
+		{LongURL: longURLs[1], ShortURL: "e"},
+		{LongURL: longURLs[2], Err: domain.ErrFailedToCreate},
+	}, results)
+}
+
 func TestFind(t *testing.T) {
 	ctl := gomock.NewController(t)
 	defer ctl.Finish()
@@ -56,14 +327,225 @@ func TestFind(t *testing.T) {
 	repo.EXPECT().Find(ctx, url.ShortURL).Return(url, nil)
 
 	cache := mock.NewMockCacher(ctl)
-	cache.EXPECT().Get(ctx, url.ShortURL).Return("", domain.ErrNotFound)
-	cache.EXPECT().Set(ctx, url.ShortURL, url.LongURL).Return(nil)
+	cache.EXPECT().Get(ctx, url.ShortURL).Return(domain.URL{}, domain.ErrNotFound)
+	cache.EXPECT().Add(ctx, url, time.Duration(0)).Return(nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
 
-	service := services.NewService(log, repo, urlgen, cache)
-	longURL, err := service.Find(ctx, url.ShortURL)
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+	found, err := service.Find(ctx, url.ShortURL)
 
 	assert.NoError(t, err)
-	assert.Equal(t, url.LongURL, longURL)
+	assert.Equal(t, url, found)
+}
+
+func TestFindExpired(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	expiresAt := time.Now().Add(-time.Minute)
+	url := domain.URL{
+		ShortURL:  "abcd",
+		LongURL:   "http://github.com",
+		ExpiresAt: &expiresAt,
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Find(ctx, url.ShortURL).Return(url, nil)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Get(ctx, url.ShortURL).Return(domain.URL{}, domain.ErrNotFound)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+	_, err := service.Find(ctx, url.ShortURL)
+
+	assert.ErrorIs(t, err, domain.ErrExpired)
+}
+
+// lookupMetrics counts lookups of service
+type lookupMetrics struct {
+	cacheMisses int64
+	executed    int64
+	coalesced   int64
+}
+
+func (m *lookupMetrics) CacheLookup(hit bool) {
+	if !hit {
+		atomic.AddInt64(&m.cacheMisses, 1)
+	}
+}
+
+func (m *lookupMetrics) StorageLookup(coalesced bool) {
+	if coalesced {
+		atomic.AddInt64(&m.coalesced, 1)
+	} else {
+		atomic.AddInt64(&m.executed, 1)
+	}
+}
+
+func TestFindCoalesced(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	const requests = 100
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	url := domain.URL{
+		ShortURL: "abcd",
+		LongURL:  "http://github.com",
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	release := make(chan struct{})
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Find(ctx, url.ShortURL).DoAndReturn(func(context.Context, string) (domain.URL, error) {
+		<-release
+
+		return url, nil
+	}).Times(1)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Get(ctx, url.ShortURL).Return(domain.URL{}, domain.ErrNotFound).Times(requests)
+	cache.EXPECT().Add(ctx, url, time.Duration(0)).Return(nil).Times(1)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	m := &lookupMetrics{}
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.Metrics(m))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			found, err := service.Find(ctx, url.ShortURL)
+			assert.NoError(t, err)
+			assert.Equal(t, url, found)
+		}()
+	}
+
+	// let every request miss cache and join storage lookup
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&m.cacheMisses) == requests
+	}, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	wg.Wait()
+
+	assert.Equal(t, int64(1), m.executed)
+	assert.Equal(t, int64(requests-1), m.coalesced)
+}
+
+func TestFindNotFound(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Find(ctx, "abcd").Return(domain.URL{}, domain.ErrNotFound)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Get(ctx, "abcd").Return(domain.URL{}, domain.ErrNotFound)
+	cache.EXPECT().SetNotFound(ctx, "abcd", time.Minute).Return(nil)
+	cache.EXPECT().Get(ctx, "abcd").Return(domain.URL{}, domain.ErrCachedNotFound)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.NotFoundTTL(time.Minute))
+
+	_, err := service.Find(ctx, "abcd")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// second lookup is answered by cache
+	_, err = service.Find(ctx, "abcd")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestFindFilter(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	url := domain.URL{
+		ShortURL: "abcd",
+		LongURL:  "http://github.com",
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Create(ctx, url).Return(nil)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, url, time.Duration(0)).Return(nil)
+	cache.EXPECT().Get(ctx, url.ShortURL).Return(url, nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.Filter(bloom.New(100, 0.01)))
+
+	// missing short url is looked up neither in cache nor in storage
+	_, err := service.Find(ctx, url.ShortURL)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	assert.NoError(t, service.CreateWithAlias(ctx, url))
+
+	found, err := service.Find(ctx, url.ShortURL)
+	assert.NoError(t, err)
+	assert.Equal(t, url, found)
+}
+
+func TestUpdate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	url := domain.URL{
+		ShortURL: "abcd",
+		LongURL:  "http://githb.com",
+		Owner:    "marketing",
+	}
+	updated := url
+	updated.LongURL = "http://github.com"
+
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Find(ctx, url.ShortURL).Return(url, nil).Times(2)
+	repo.EXPECT().Update(ctx, updated).Return(nil)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, updated, time.Duration(0)).Return(nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+
+	err := service.Update(ctx, url.ShortURL, updated.LongURL, domain.APIKey{Owner: "sales"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	err = service.Update(ctx, url.ShortURL, updated.LongURL, domain.APIKey{Owner: url.Owner})
+	assert.NoError(t, err)
 }
 
 func TestDelete(t *testing.T) {
@@ -76,17 +558,316 @@ func TestDelete(t *testing.T) {
 	url := domain.URL{
 		ShortURL: "abcd",
 		LongURL:  "http://github.com",
+		Owner:    "marketing",
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Find(ctx, url.ShortURL).Return(url, nil).Times(2)
+	repo.EXPECT().Delete(ctx, url.ShortURL, url.Owner).Return(nil)
+
+	// tombstone replaces cached url
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, gomock.Any(), time.Duration(0)).DoAndReturn(func(_ context.Context, cached domain.URL, _ time.Duration) error {
+		assert.True(t, cached.IsDeleted())
+		assert.Equal(t, url.Owner, cached.DeletedBy)
+
+		return nil
+	})
+
+	audit := mock.NewMockAuditRepository(ctl)
+	audit.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, event domain.AuditEvent) error {
+		assert.Equal(t, domain.AuditDeleted, event.Action)
+		assert.Equal(t, url.Owner, event.Actor)
+
+		return nil
+	})
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.Audit(audit))
+
+	err := service.Delete(ctx, url.ShortURL, domain.APIKey{Owner: "sales"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	err = service.Delete(ctx, url.ShortURL, domain.APIKey{Owner: url.Owner})
+	assert.NoError(t, err)
+}
+
+func TestRestore(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	deletedAt := time.Now()
+	deleted := domain.URL{
+		ShortURL:  "abcd",
+		LongURL:   "http://github.com",
+		Owner:     "marketing",
+		DeletedAt: &deletedAt,
+		DeletedBy: "marketing",
+	}
+	restored := domain.URL{
+		ShortURL: deleted.ShortURL,
+		LongURL:  deleted.LongURL,
+		Owner:    deleted.Owner,
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	gomock.InOrder(
+		repo.EXPECT().Find(ctx, deleted.ShortURL).Return(deleted, nil),
+		repo.EXPECT().Restore(ctx, deleted.ShortURL).Return(nil),
+		repo.EXPECT().Find(ctx, deleted.ShortURL).Return(restored, nil),
+	)
+
+	// restored url replaces cached tombstone or cached missing short url
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Set(ctx, restored, time.Duration(0)).Return(nil)
+
+	audit := mock.NewMockAuditRepository(ctl)
+	audit.EXPECT().Append(ctx, gomock.Any()).Return(nil)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.Audit(audit))
+
+	err := service.Restore(ctx, deleted.ShortURL, domain.APIKey{Owner: deleted.Owner})
+	assert.NoError(t, err)
+
+	err = service.Restore(ctx, deleted.ShortURL, domain.APIKey{Owner: deleted.Owner})
+	assert.ErrorIs(t, err, domain.ErrNotDeleted)
+}
+
+func TestFindDeleted(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	deletedAt := time.Now()
+	url := domain.URL{
+		ShortURL:  "abcd",
+		LongURL:   "http://github.com",
+		DeletedAt: &deletedAt,
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Find(ctx, url.ShortURL).Return(url, nil)
+
+	cache := mock.NewMockCacher(ctl)
+	gomock.InOrder(
+		cache.EXPECT().Get(ctx, url.ShortURL).Return(domain.URL{}, domain.ErrNotFound),
+		cache.EXPECT().Add(ctx, url, time.Duration(0)).Return(nil),
+		cache.EXPECT().Get(ctx, url.ShortURL).Return(url, nil),
+	)
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+
+	_, err := service.Find(ctx, url.ShortURL)
+	assert.ErrorIs(t, err, domain.ErrDeleted)
+
+	// tombstone is served from cache
+	_, err = service.Find(ctx, url.ShortURL)
+	assert.ErrorIs(t, err, domain.ErrDeleted)
+}
+
+func TestUnlock(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	hash, err := password.Hash("secret")
+	assert.NoError(t, err)
+
+	url := domain.URL{
+		ShortURL:     "abcd",
+		LongURL:      "http://github.com",
+		PasswordHash: hash,
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+	repo := mock.NewMockRepository(ctl)
+
+	cache := mock.NewMockCacher(ctl)
+	cache.EXPECT().Get(ctx, url.ShortURL).Return(url, nil).AnyTimes()
+
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks, services.PasswordAttempts(ratelimit.NewMemoryAttempts(2, time.Hour)))
+
+	client := "192.0.2.1"
+
+	found, err := service.Unlock(ctx, url.ShortURL, "secret", client)
+	assert.NoError(t, err)
+	assert.Equal(t, url, found)
+
+	// correct password does not use up attempts
+	_, err = service.Unlock(ctx, url.ShortURL, "secret", client)
+	assert.NoError(t, err)
+
+	_, err = service.Unlock(ctx, url.ShortURL, "wrong", client)
+	assert.ErrorIs(t, err, domain.ErrWrongPassword)
+
+	_, err = service.Unlock(ctx, url.ShortURL, "wrong", client)
+	assert.ErrorIs(t, err, domain.ErrWrongPassword)
+
+	// even correct password is rejected until window passes
+	_, err = service.Unlock(ctx, url.ShortURL, "secret", client)
+	assert.ErrorIs(t, err, domain.ErrTooManyAttempts)
+
+	// other clients are not locked out
+	found, err = service.Unlock(ctx, url.ShortURL, "secret", "192.0.2.2")
+	assert.NoError(t, err)
+	assert.Equal(t, url, found)
+
+	// public url does not need password
+	public := domain.URL{ShortURL: "efgh", LongURL: "http://github.com"}
+	cache.EXPECT().Get(ctx, public.ShortURL).Return(public, nil)
+
+	found, err = service.Unlock(ctx, public.ShortURL, "", client)
+	assert.NoError(t, err)
+	assert.Equal(t, public, found)
+}
+
+func TestStats(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	url := domain.URL{
+		ShortURL: "abcd",
+		LongURL:  "http://github.com",
+		Owner:    "marketing",
+	}
+	stats := domain.Stats{
+		ShortURL:  url.ShortURL,
+		Total:     3,
+		Daily:     []domain.DailyStat{{Day: "2022-10-01", Clicks: 3}},
+		Referrers: []domain.ReferrerStat{{Referrer: "https://google.com", Clicks: 3}},
+	}
+	urlgen := mock.NewMockShortURLGenerator(ctl)
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().Find(ctx, url.ShortURL).Return(url, nil).Times(3)
+	repo.EXPECT().Find(ctx, "bbbb").Return(domain.URL{}, domain.ErrNotFound)
+
+	cache := mock.NewMockCacher(ctl)
+
+	clicks := mock.NewMockClickRepository(ctl)
+	clicks.EXPECT().Stats(ctx, url.ShortURL).Return(stats, nil).Times(2)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+
+	got, err := service.Stats(ctx, url.ShortURL, domain.APIKey{Owner: "marketing"})
+	assert.NoError(t, err)
+	assert.Equal(t, stats, got)
+
+	_, err = service.Stats(ctx, url.ShortURL, domain.APIKey{Owner: "support", Admin: true})
+	assert.NoError(t, err)
+
+	// stats of other owners are not readable
+	_, err = service.Stats(ctx, url.ShortURL, domain.APIKey{Owner: "support"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = service.Stats(ctx, "bbbb", domain.APIKey{Owner: "marketing"})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestInfo(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	created := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	url := domain.URL{
+		ShortURL:  "abcd",
+		LongURL:   "http://github.com",
+		Title:     "GitHub",
+		Tags:      []string{"code"},
+		Notes:     "landing page",
+		Owner:     "marketing",
+		CreatedAt: created,
+		UpdatedAt: created,
 	}
+
 	urlgen := mock.NewMockShortURLGenerator(ctl)
 
 	repo := mock.NewMockRepository(ctl)
-	repo.EXPECT().Delete(ctx, url.ShortURL).Return(nil)
+	repo.EXPECT().Find(ctx, url.ShortURL).Return(url, nil).Times(3)
+	repo.EXPECT().Find(ctx, "bbbb").Return(domain.URL{}, domain.ErrNotFound)
+
+	cache := mock.NewMockCacher(ctl)
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
+
+	got, err := service.Info(ctx, url.ShortURL, domain.APIKey{Owner: "marketing"})
+	assert.NoError(t, err)
+	assert.Equal(t, url, got)
+
+	_, err = service.Info(ctx, url.ShortURL, domain.APIKey{Owner: "support", Admin: true})
+	assert.NoError(t, err)
+
+	// metadata of other owners is not readable
+	_, err = service.Info(ctx, url.ShortURL, domain.APIKey{Owner: "support"})
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = service.Info(ctx, "bbbb", domain.APIKey{Owner: "marketing"})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestList(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+
+	ctx := context.Background()
+	log := logger.NewDebugLogger()
+
+	created := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	urls := []domain.URL{
+		{ShortURL: "abcd", Owner: "dev", CreatedAt: created.Add(time.Hour)},
+		{ShortURL: "abce", Owner: "dev", CreatedAt: created},
+		{ShortURL: "abcf", Owner: "dev", CreatedAt: created},
+	}
+	key := domain.APIKey{Owner: "dev"}
+
+	repo := mock.NewMockRepository(ctl)
+	repo.EXPECT().List(ctx, domain.ListQuery{
+		Filter: domain.ListFilter{Owner: "dev"},
+		Sort:   domain.SortCreatedDesc,
+		Limit:  3,
+	}).Return(urls, nil)
 
+	urlgen := mock.NewMockShortURLGenerator(ctl)
 	cache := mock.NewMockCacher(ctl)
-	cache.EXPECT().Del(ctx, url.ShortURL).Return(nil)
+	clicks := mock.NewMockClickRepository(ctl)
+
+	service := services.NewService(log, repo, urlgen, cache, clicks)
 
-	service := services.NewService(log, repo, urlgen, cache)
-	err := service.Delete(ctx, url.ShortURL)
+	// owner of key is forced for non admins
+	page, err := service.List(ctx, domain.ListQuery{Sort: domain.SortCreatedDesc, Limit: 2}, key)
+	assert.NoError(t, err)
+	assert.Equal(t, urls[:2], page.URLs)
 
+	cursor, err := domain.DecodeListCursor(page.NextCursor)
 	assert.NoError(t, err)
+	assert.Equal(t, domain.NewListCursor(domain.SortCreatedDesc, urls[1]), cursor)
+
+	_, err = service.List(ctx, domain.ListQuery{Filter: domain.ListFilter{Owner: "marketing"}, Sort: domain.SortCreatedDesc}, key)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = service.List(ctx, domain.ListQuery{Sort: domain.SortShortURLAsc, After: &cursor}, key)
+	assert.ErrorIs(t, err, domain.ErrInvalidCursor)
 }
@@ -0,0 +1,68 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/shalimski/shortener/internal/domain"
+	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+type ctxKey int
+
+const (
+	apiKeyCtxKey ctxKey = iota
+	itemLimitCtxKey
+)
+
+// Auth middleware authenticates requests by api key from X-API-Key header
+// and puts the key to request context
+func Auth(auth ports.AuthService, log *logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			key, err := auth.Authenticate(ctx, r.Header.Get(apiKeyHeader))
+			if err != nil {
+				status, message := http.StatusUnauthorized, "invalid api key"
+				if !errors.Is(err, domain.ErrUnauthorized) {
+					log.Error(ctx, "failed to authenticate", zap.Error(err))
+					status, message = http.StatusInternalServerError, "failed to authenticate"
+				}
+
+				if err = Respond(ctx, w, NewResponse(message), status); err != nil {
+					log.Error(ctx, "failed to respond", zap.Error(err))
+				}
+
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, apiKeyCtxKey, key)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// apiKey returns api key of authenticated request,
+// when authentication is disabled every request acts as admin
+func apiKey(ctx context.Context) domain.APIKey {
+	key, ok := authenticated(ctx)
+	if !ok {
+		return domain.APIKey{Admin: true}
+	}
+
+	return key
+}
+
+// authenticated returns api key put to context by Auth middleware
+func authenticated(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey).(domain.APIKey)
+
+	return key, ok
+}
@@ -1,13 +1,133 @@
 package web
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shalimski/shortener/internal/domain"
+)
+
+// limits of link metadata
+const (
+	maxTitleLength = 200
+	maxNotesLength = 2000
+	maxTags        = 20
+	maxTagLength   = 50
+)
+
+// maxTTL limits ttl of link in seconds to 100 years, so expiration never overflows time.Duration
+const maxTTL = 100 * 365 * 24 * 60 * 60
+
+var (
+	errExpirationConflict = errors.New("expires_at and ttl are mutually exclusive")
+	errInvalidExpiration  = fmt.Errorf("expiration must be in the future and ttl at most %d seconds", maxTTL)
+	errInvalidTitle       = fmt.Errorf("title must be at most %d characters", maxTitleLength)
+	errInvalidNotes       = fmt.Errorf("notes must be at most %d characters", maxNotesLength)
+	errInvalidTags        = fmt.Errorf("at most %d tags of 1 to %d characters are allowed", maxTags, maxTagLength)
+)
+
 type CreateURLDTO struct {
-	LongURL string `json:"long_url"`
+	LongURL      string     `json:"long_url"`
+	Alias        string     `json:"alias,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	TTL          int64      `json:"ttl,omitempty"`           // lifetime in seconds
+	RedirectCode int        `json:"redirect_code,omitempty"` // 301, 302, 307 or 308, default is configured
+	Title        string     `json:"title,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	Password     string     `json:"password,omitempty"` // link redirects only after password is entered
+}
+
+// Metadata returns validated title, tags and notes, tags are trimmed and repeated tags are dropped
+func (d CreateURLDTO) Metadata() (title string, tags []string, notes string, err error) {
+	title = strings.TrimSpace(d.Title)
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return "", nil, "", errInvalidTitle
+	}
+
+	if utf8.RuneCountInString(d.Notes) > maxNotesLength {
+		return "", nil, "", errInvalidNotes
+	}
+
+	if len(d.Tags) > maxTags {
+		return "", nil, "", errInvalidTags
+	}
+
+	seen := make(map[string]bool, len(d.Tags))
+
+	for _, tag := range d.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return "", nil, "", errInvalidTags
+		}
+
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return title, tags, d.Notes, nil
+}
+
+// Expiration returns absolute expiration time of the url, nil means url never expires
+func (d CreateURLDTO) Expiration(now time.Time) (*time.Time, error) {
+	if d.ExpiresAt != nil && d.TTL != 0 {
+		return nil, errExpirationConflict
+	}
+
+	if d.TTL < 0 || d.TTL > maxTTL {
+		return nil, errInvalidExpiration
+	}
+
+	if d.TTL > 0 {
+		expiresAt := now.Add(time.Duration(d.TTL) * time.Second)
+
+		return &expiresAt, nil
+	}
+
+	if d.ExpiresAt != nil && !d.ExpiresAt.After(now) {
+		return nil, errInvalidExpiration
+	}
+
+	return d.ExpiresAt, nil
 }
 
 type ResponseCreateDTO struct {
 	ShortURL string `json:"short_url"`
 }
 
+type UpdateURLDTO struct {
+	LongURL string `json:"long_url"`
+}
+
+type CreateBatchDTO struct {
+	LongURLs []string `json:"long_urls"`
+}
+
+type ResponseBatchItemDTO struct {
+	LongURL  string `json:"long_url"`
+	ShortURL string `json:"short_url,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type ResponseBatchDTO struct {
+	Results []ResponseBatchItemDTO `json:"results"`
+}
+
+type ResponseListDTO struct {
+	Links      []domain.URL `json:"links"`
+	NextCursor string       `json:"next_cursor,omitempty"` // empty on last page
+}
+
+type ResponseHistoryDTO struct {
+	ShortURL string              `json:"short_url"`
+	Events   []domain.AuditEvent `json:"events"` // oldest first
+}
+
 type ResponseMessage struct {
 	Message string `json:"message"`
 }
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiration(t *testing.T) {
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	maxExpiresAt := now.Add(maxTTL * time.Second)
+
+	tests := []struct {
+		name    string
+		dto     CreateURLDTO
+		want    *time.Time
+		wantErr error
+	}{
+		{"never expires", CreateURLDTO{}, nil, nil},
+		{"ttl", CreateURLDTO{TTL: 3600}, &future, nil},
+		{"max ttl", CreateURLDTO{TTL: maxTTL}, &maxExpiresAt, nil},
+		{"ttl above max", CreateURLDTO{TTL: maxTTL + 1}, nil, errInvalidExpiration},
+		{"ttl overflowing duration", CreateURLDTO{TTL: math.MaxInt64 / 1000}, nil, errInvalidExpiration},
+		{"negative ttl", CreateURLDTO{TTL: -1}, nil, errInvalidExpiration},
+		{"expires at", CreateURLDTO{ExpiresAt: &future}, &future, nil},
+		{"expires at in the past", CreateURLDTO{ExpiresAt: &past}, nil, errInvalidExpiration},
+		{"both", CreateURLDTO{ExpiresAt: &future, TTL: 3600}, nil, errExpirationConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.dto.Expiration(now)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"net/http"
+)
+
+// passwordField is name of form field holding password of protected url
+const passwordField = "password"
+
+// maxFormSize limits body of submitted password form
+const maxFormSize = 4 << 10
+
+// passwordForm is page asking for password of protected url, it is submitted to url of the page
+var passwordForm = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Protected link</title>
+</head>
+<body>
+<form method="post">
+<p>This link is protected by a password.</p>
+{{if .}}<p role="alert">{{.}}</p>
+{{end}}<input type="password" name="` + passwordField + `" autofocus required autocomplete="current-password">
+<button type="submit">Open</button>
+</form>
+</body>
+</html>
+`))
+
+// RespondPasswordForm sends page asking for password with optional message about previous attempt.
+func RespondPasswordForm(ctx context.Context, w http.ResponseWriter, message string, statusCode int) error {
+	var buf bytes.Buffer
+	if err := passwordForm.Execute(&buf, message); err != nil {
+		return err
+	}
+
+	// Page must not be cached or framed by other sites.
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,30 +2,59 @@ package web
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/shalimski/shortener/config"
 	"github.com/shalimski/shortener/internal/domain"
 	"github.com/shalimski/shortener/internal/ports"
+	"github.com/shalimski/shortener/pkg/clientip"
 	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/shalimski/shortener/pkg/password"
 	"github.com/shalimski/shortener/pkg/urlvalidator"
 	"go.uber.org/zap"
 )
 
-const shortURLParam = "shortURL"
+const (
+	shortURLParam        = "shortURL"
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	maxPageSize = 1000
+)
 
 type Handler struct {
 	log                 *logger.Logger
 	urlShortenerService ports.ShortenerService
+	clicks              ports.ClickRecorder
+	ips                 *clientip.Resolver // client addresses counting wrong passwords
+	countryHeader       string
+	maxBatchSize        int
+	redirectCode        int    // default redirect code for urls without own one
+	alphabet            string // characters of generated short urls
+	maxLength           int    // max length of short urls and aliases
 }
 
-func NewHandler(service ports.ShortenerService, log *logger.Logger) *Handler {
+func NewHandler(cfg *config.Config, service ports.ShortenerService, clicks ports.ClickRecorder, ips *clientip.Resolver, log *logger.Logger) *Handler {
 	return &Handler{
 		urlShortenerService: service,
+		clicks:              clicks,
+		ips:                 ips,
+		countryHeader:       cfg.Analytics.CountryHeader,
+		maxBatchSize:        cfg.App.MaxBatchSize,
+		redirectCode:        cfg.App.RedirectCode,
+		alphabet:            cfg.App.Alphabet,
+		maxLength:           cfg.App.MaxLength,
 		log:                 log,
 	}
 }
 
+// isShortURL reports whether short url of request is a generated short url or a custom alias
+func (h *Handler) isShortURL(shortURL string) bool {
+	return urlvalidator.IsShortURLSuffix(shortURL, h.alphabet, h.maxLength) || urlvalidator.IsAlias(shortURL, h.maxLength)
+}
+
 // Create handler validate request, create new short url and respond it
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -60,8 +89,122 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if data.Alias != "" && (!urlvalidator.IsAlias(data.Alias, h.maxLength) || domain.IsReservedShortURL(data.Alias)) {
+		h.log.Info(ctx, "invalid alias", zap.String("alias", data.Alias))
+		err = Respond(ctx, w, NewResponse("invalid alias"), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if data.RedirectCode != 0 && !domain.IsRedirectCode(data.RedirectCode) {
+		h.log.Info(ctx, "invalid redirect code", zap.Int("redirectCode", data.RedirectCode))
+		err = Respond(ctx, w, NewResponse("invalid redirect code, allowed: 301, 302, 307, 308"), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	expiresAt, err := data.Expiration(time.Now())
+	if err != nil {
+		h.log.Info(ctx, "invalid expiration", zap.Error(err))
+		err = Respond(ctx, w, NewResponse(err.Error()), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	title, tags, notes, err := data.Metadata()
+	if err != nil {
+		h.log.Info(ctx, "invalid metadata", zap.Error(err))
+		err = Respond(ctx, w, NewResponse(err.Error()), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	var passwordHash string
+	if data.Password != "" {
+		passwordHash, err = password.Hash(data.Password)
+	}
+
+	if err != nil && errors.Is(err, password.ErrInvalidLength) {
+		h.log.Info(ctx, "invalid password")
+		err = Respond(ctx, w, NewResponse(err.Error()), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Error(ctx, "failed to hash password", zap.Error(err))
+		err = Respond(ctx, w, NewResponse("failed to create url"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	url := domain.URL{
+		ShortURL:       data.Alias,
+		LongURL:        data.LongURL,
+		ExpiresAt:      expiresAt,
+		IdempotencyKey: r.Header.Get(idempotencyKeyHeader),
+		Owner:          apiKey(ctx).Owner,
+		RedirectCode:   data.RedirectCode,
+		Title:          title,
+		Tags:           tags,
+		Notes:          notes,
+		PasswordHash:   passwordHash,
+	}
+
 	// Create short link
-	shortURL, err := h.urlShortenerService.Create(ctx, data.LongURL)
+	shortURL := data.Alias
+	if shortURL != "" {
+		err = h.urlShortenerService.CreateWithAlias(ctx, url)
+	} else {
+		shortURL, err = h.urlShortenerService.Create(ctx, url)
+	}
+
+	if err != nil && errors.Is(err, domain.ErrKeyReused) {
+		h.log.Info(ctx, "idempotency key reused", zap.String("key", url.IdempotencyKey))
+		err = Respond(ctx, w, NewResponse("idempotency key is already used for another long url"), http.StatusUnprocessableEntity)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrAlreadyExists) {
+		h.log.Info(ctx, "short url already exists", zap.String("shortURL", shortURL))
+		err = Respond(ctx, w, NewResponse("short url already exists"), http.StatusConflict)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
 	if err != nil {
 		h.log.Error(ctx, "failed to create url", zap.String("longURL", data.LongURL), zap.Error(err))
 		err = Respond(ctx, w, NewResponse("failed to create url"), http.StatusInternalServerError)
@@ -79,6 +222,85 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CreateBatch handler validate every long url of request, create short urls and respond result of each
+func (h *Handler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start create batch handler")
+
+	// Reading body
+	var data CreateBatchDTO
+
+	err := Decode(r, &data)
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log.Info(ctx, "failed to parse body")
+		err = Respond(ctx, w, NewResponse(err.Error()), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	// Validation
+	if len(data.LongURLs) == 0 || len(data.LongURLs) > h.maxBatchSize {
+		h.log.Info(ctx, "invalid batch size", zap.Int("size", len(data.LongURLs)))
+		err = Respond(ctx, w, NewResponse(fmt.Sprintf("batch must contain from 1 to %d long urls", h.maxBatchSize)), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	// every long url is charged as a separate create request
+	if !allowItems(ctx, w, len(data.LongURLs), h.log) {
+		return
+	}
+
+	owner := apiKey(ctx).Owner
+	items := make([]ResponseBatchItemDTO, len(data.LongURLs))
+	valid := make([]int, 0, len(data.LongURLs)) // indexes of valid long urls
+	urls := make([]domain.URL, 0, len(data.LongURLs))
+
+	for i, longURL := range data.LongURLs {
+		items[i].LongURL = longURL
+
+		if !urlvalidator.IsURL(longURL) {
+			items[i].Error = "invalid long url"
+
+			continue
+		}
+
+		valid = append(valid, i)
+		urls = append(urls, domain.URL{LongURL: longURL, Owner: owner})
+	}
+
+	// Create short links
+	if len(urls) != 0 {
+		results := h.urlShortenerService.CreateBatch(ctx, urls)
+
+		for i, res := range results {
+			if res.Err != nil {
+				h.log.Info(ctx, "failed to create url", zap.String("longURL", res.LongURL), zap.Error(res.Err))
+				items[valid[i]].Error = "failed to create url"
+
+				continue
+			}
+
+			items[valid[i]].ShortURL = res.ShortURL
+		}
+	}
+
+	err = Respond(ctx, w, ResponseBatchDTO{Results: items}, http.StatusOK)
+	if err != nil {
+		h.log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+}
+
 // Find handler vaдidate request, finds and redirects to long url
 func (h *Handler) Find(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -87,7 +309,7 @@ func (h *Handler) Find(w http.ResponseWriter, r *http.Request) {
 	shortURL := chi.URLParam(r, shortURLParam)
 
 	// Validation
-	if !urlvalidator.IsShortURLSuffix(shortURL) {
+	if !h.isShortURL(shortURL) {
 		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
 
 		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
@@ -98,7 +320,7 @@ func (h *Handler) Find(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	longURL, err := h.urlShortenerService.Find(ctx, shortURL)
+	url, err := h.urlShortenerService.Find(ctx, shortURL)
 
 	if err != nil && errors.Is(err, domain.ErrNotFound) {
 		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
@@ -109,6 +331,24 @@ func (h *Handler) Find(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err != nil && errors.Is(err, domain.ErrExpired) {
+		err = Respond(ctx, w, NewResponse("short url expired"), http.StatusGone)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrDeleted) {
+		err = Respond(ctx, w, NewResponse("short url deleted"), http.StatusGone)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
 	if err != nil {
 		h.log.Info(ctx, "failed to find", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
 		err = Respond(ctx, w, NewResponse("failed to find"), http.StatusInternalServerError)
@@ -120,18 +360,35 @@ func (h *Handler) Find(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.Redirect(w, r, longURL, http.StatusMovedPermanently)
+	// click of protected url is recorded when password is entered
+	if url.IsProtected() {
+		err = RespondPasswordForm(ctx, w, "", http.StatusOK)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	h.recordClick(r, shortURL)
+
+	code := h.redirectCode
+	if url.RedirectCode != 0 {
+		code = url.RedirectCode
+	}
+
+	http.Redirect(w, r, url.LongURL, code)
 }
 
-// Delete handler validate request and delete short url value
-func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+// Unlock handler checks password submitted by form of protected url and redirects to long url
+func (h *Handler) Unlock(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	h.log.Info(ctx, "start delete handler")
+	h.log.Info(ctx, "start unlock handler")
 
 	shortURL := chi.URLParam(r, shortURLParam)
 
 	// Validation
-	if !urlvalidator.IsShortURLSuffix(shortURL) {
+	if !h.isShortURL(shortURL) {
 		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
 
 		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
@@ -142,11 +399,64 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.urlShortenerService.Delete(ctx, shortURL)
+	r.Body = http.MaxBytesReader(w, r.Body, maxFormSize)
+
+	err := r.ParseForm()
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log.Info(ctx, "failed to parse form")
+		err = RespondPasswordForm(ctx, w, "Invalid form, try again.", http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	url, err := h.urlShortenerService.Unlock(ctx, shortURL, r.PostForm.Get(passwordField), h.ips.IP(r))
+
+	if err != nil && errors.Is(err, domain.ErrWrongPassword) {
+		h.log.Info(ctx, "wrong password", zap.String("shortURL", shortURL))
+		err = RespondPasswordForm(ctx, w, "Wrong password, try again.", http.StatusUnauthorized)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrTooManyAttempts) {
+		err = RespondPasswordForm(ctx, w, "Too many wrong passwords, try again later.", http.StatusTooManyRequests)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
 
 	if err != nil && errors.Is(err, domain.ErrNotFound) {
 		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrExpired) {
+		err = Respond(ctx, w, NewResponse("short url expired"), http.StatusGone)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
 
+	if err != nil && errors.Is(err, domain.ErrDeleted) {
+		err = Respond(ctx, w, NewResponse("short url deleted"), http.StatusGone)
 		if err != nil {
 			h.log.Error(ctx, "failed to respond", zap.Error(err))
 		}
@@ -155,10 +465,45 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err != nil {
-		h.log.Info(ctx, "failed to delete", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+		h.log.Info(ctx, "failed to unlock", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+		err = Respond(ctx, w, NewResponse("failed to find"), http.StatusInternalServerError)
 
-		err = Respond(ctx, w, NewResponse("failed to delete"), http.StatusInternalServerError)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	h.recordClick(r, shortURL)
+
+	// 303 makes browser follow with GET, 307 and 308 would resend password to long url
+	http.Redirect(w, r, url.LongURL, http.StatusSeeOther)
+}
 
+// recordClick of short url by request redirected to long url
+func (h *Handler) recordClick(r *http.Request, shortURL string) {
+	h.clicks.Record(r.Context(), domain.Click{
+		ShortURL:  shortURL,
+		Timestamp: time.Now().UTC(),
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		Country:   r.Header.Get(h.countryHeader),
+	})
+}
+
+// Stats handler validate request and respond clicks statistics of short url
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start stats handler")
+
+	shortURL := chi.URLParam(r, shortURLParam)
+
+	// Validation
+	if !h.isShortURL(shortURL) {
+		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
+
+		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
 		if err != nil {
 			h.log.Error(ctx, "failed to respond", zap.Error(err))
 		}
@@ -166,7 +511,458 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = Respond(ctx, w, NewResponse("url deleted"), http.StatusOK)
+	stats, err := h.urlShortenerService.Stats(ctx, shortURL, apiKey(ctx))
+
+	if err != nil && errors.Is(err, domain.ErrNotFound) {
+		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrForbidden) {
+		err = Respond(ctx, w, NewResponse("short url owned by another api key"), http.StatusForbidden)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Info(ctx, "failed to get stats", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+		err = Respond(ctx, w, NewResponse("failed to get stats"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = Respond(ctx, w, stats, http.StatusOK)
+	if err != nil {
+		h.log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+}
+
+// Info handler validate request and respond short url with its metadata without redirect
+func (h *Handler) Info(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start info handler")
+
+	shortURL := chi.URLParam(r, shortURLParam)
+
+	// Validation
+	if !h.isShortURL(shortURL) {
+		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
+
+		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	url, err := h.urlShortenerService.Info(ctx, shortURL, apiKey(ctx))
+
+	if err != nil && errors.Is(err, domain.ErrNotFound) {
+		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrForbidden) {
+		err = Respond(ctx, w, NewResponse("short url owned by another api key"), http.StatusForbidden)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Info(ctx, "failed to get info", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+		err = Respond(ctx, w, NewResponse("failed to get info"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = Respond(ctx, w, url, http.StatusOK)
+	if err != nil {
+		h.log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+}
+
+// Update handler validate request and change long url of short url
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start update handler")
+
+	shortURL := chi.URLParam(r, shortURLParam)
+
+	// Validation
+	if !h.isShortURL(shortURL) {
+		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
+
+		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	// Reading body
+	var data UpdateURLDTO
+
+	err := Decode(r, &data)
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log.Info(ctx, "failed to parse body")
+		err = Respond(ctx, w, NewResponse(err.Error()), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if !urlvalidator.IsURL(data.LongURL) {
+		h.log.Info(ctx, "invalid long url", zap.String("longURL", data.LongURL))
+		err = Respond(ctx, w, NewResponse("invalid long url"), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = h.urlShortenerService.Update(ctx, shortURL, data.LongURL, apiKey(ctx))
+
+	if err != nil && errors.Is(err, domain.ErrNotFound) {
+		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrExpired) {
+		err = Respond(ctx, w, NewResponse("short url expired"), http.StatusGone)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrDeleted) {
+		err = Respond(ctx, w, NewResponse("short url deleted"), http.StatusGone)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrForbidden) {
+		err = Respond(ctx, w, NewResponse("short url owned by another api key"), http.StatusForbidden)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Info(ctx, "failed to update", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+
+		err = Respond(ctx, w, NewResponse("failed to update"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = Respond(ctx, w, NewResponse("url updated"), http.StatusOK)
+	if err != nil {
+		h.log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+}
+
+// Delete handler validate request and delete short url value
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start delete handler")
+
+	shortURL := chi.URLParam(r, shortURLParam)
+
+	// Validation
+	if !h.isShortURL(shortURL) {
+		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
+
+		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err := h.urlShortenerService.Delete(ctx, shortURL, apiKey(ctx))
+
+	if err != nil && errors.Is(err, domain.ErrNotFound) {
+		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrDeleted) {
+		err = Respond(ctx, w, NewResponse("short url deleted"), http.StatusGone)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrForbidden) {
+		err = Respond(ctx, w, NewResponse("short url owned by another api key"), http.StatusForbidden)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Info(ctx, "failed to delete", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+
+		err = Respond(ctx, w, NewResponse("failed to delete"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = Respond(ctx, w, NewResponse("url deleted"), http.StatusOK)
+	if err != nil {
+		h.log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+}
+
+// Restore handler validate request and restore deleted short url
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start restore handler")
+
+	shortURL := chi.URLParam(r, shortURLParam)
+
+	// Validation
+	if !h.isShortURL(shortURL) {
+		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
+
+		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err := h.urlShortenerService.Restore(ctx, shortURL, apiKey(ctx))
+
+	if err != nil && errors.Is(err, domain.ErrNotFound) {
+		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrNotDeleted) {
+		err = Respond(ctx, w, NewResponse("short url is not deleted"), http.StatusConflict)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrForbidden) {
+		err = Respond(ctx, w, NewResponse("short url owned by another api key"), http.StatusForbidden)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Info(ctx, "failed to restore", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+
+		err = Respond(ctx, w, NewResponse("failed to restore"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = Respond(ctx, w, NewResponse("url restored"), http.StatusOK)
+	if err != nil {
+		h.log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+}
+
+// History handler validate request and respond audit log of short url
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start history handler")
+
+	shortURL := chi.URLParam(r, shortURLParam)
+
+	// Validation
+	if !h.isShortURL(shortURL) {
+		h.log.Info(ctx, "invalid short url", zap.String("shortURL", shortURL))
+
+		err := Respond(ctx, w, NewResponse("invalid short url"), http.StatusBadRequest)
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	events, err := h.urlShortenerService.History(ctx, shortURL, apiKey(ctx))
+
+	if err != nil && errors.Is(err, domain.ErrNotFound) {
+		err = Respond(ctx, w, NewResponse("short url not found"), http.StatusNotFound)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrForbidden) {
+		err = Respond(ctx, w, NewResponse("short url owned by another api key"), http.StatusForbidden)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Info(ctx, "failed to get history", zap.String("shortURL", shortURL), zap.String("error", err.Error()))
+
+		err = Respond(ctx, w, NewResponse("failed to get history"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = Respond(ctx, w, ResponseHistoryDTO{ShortURL: shortURL, Events: events}, http.StatusOK)
+	if err != nil {
+		h.log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+}
+
+// List handler validate query and respond page of links with cursor of next page
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	h.log.Info(ctx, "start list handler")
+
+	// Validation
+	query, err := listQuery(r.URL.Query())
+	if err != nil {
+		h.log.Info(ctx, "invalid list query", zap.Error(err))
+		err = Respond(ctx, w, NewResponse(err.Error()), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	page, err := h.urlShortenerService.List(ctx, query, apiKey(ctx))
+
+	if err != nil && errors.Is(err, domain.ErrInvalidCursor) {
+		err = Respond(ctx, w, NewResponse("invalid cursor"), http.StatusBadRequest)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil && errors.Is(err, domain.ErrForbidden) {
+		err = Respond(ctx, w, NewResponse("links of another owner are not allowed"), http.StatusForbidden)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	if err != nil {
+		h.log.Info(ctx, "failed to list", zap.String("error", err.Error()))
+
+		err = Respond(ctx, w, NewResponse("failed to list"), http.StatusInternalServerError)
+
+		if err != nil {
+			h.log.Error(ctx, "failed to respond", zap.Error(err))
+		}
+
+		return
+	}
+
+	err = Respond(ctx, w, ResponseListDTO{Links: page.URLs, NextCursor: page.NextCursor}, http.StatusOK)
 	if err != nil {
 		h.log.Error(ctx, "failed to respond", zap.Error(err))
 	}
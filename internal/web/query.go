@@ -0,0 +1,96 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/shalimski/shortener/internal/domain"
+)
+
+var (
+	errInvalidLimit   = fmt.Errorf("limit must be from 1 to %d", maxPageSize)
+	errInvalidSort    = errors.New("sort must be one of created_at, -created_at, short_url, -short_url")
+	errInvalidRange   = errors.New("created_after must be before created_before")
+	errInvalidDeleted = errors.New("deleted must be true or false")
+)
+
+// listQuery parses query of links listing, links are sorted from newest by default
+func listQuery(values url.Values) (domain.ListQuery, error) {
+	query := domain.ListQuery{
+		Filter: domain.ListFilter{
+			Owner:  values.Get("owner"),
+			Domain: values.Get("domain"),
+			Tag:    values.Get("tag"),
+		},
+		Sort: domain.SortCreatedDesc,
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return domain.ListQuery{}, errInvalidLimit
+		}
+
+		query.Limit = limit
+	}
+
+	if v := values.Get("deleted"); v != "" {
+		deleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return domain.ListQuery{}, errInvalidDeleted
+		}
+
+		query.Filter.Deleted = deleted
+	}
+
+	if v := values.Get("sort"); v != "" {
+		query.Sort = domain.ListSort(v)
+		if !query.Sort.IsValid() {
+			return domain.ListQuery{}, errInvalidSort
+		}
+	}
+
+	var err error
+
+	if query.Filter.CreatedAfter, err = parseTime(values, "created_after"); err != nil {
+		return domain.ListQuery{}, err
+	}
+
+	if query.Filter.CreatedBefore, err = parseTime(values, "created_before"); err != nil {
+		return domain.ListQuery{}, err
+	}
+
+	if !query.Filter.CreatedAfter.IsZero() && !query.Filter.CreatedBefore.IsZero() &&
+		!query.Filter.CreatedAfter.Before(query.Filter.CreatedBefore) {
+		return domain.ListQuery{}, errInvalidRange
+	}
+
+	if v := values.Get("cursor"); v != "" {
+		cursor, err := domain.DecodeListCursor(v)
+		if err != nil {
+			return domain.ListQuery{}, err
+		}
+
+		query.After = &cursor
+	}
+
+	return query, nil
+}
+
+// parseTime parses optional RFC 3339 time of query parameter, missing parameter is zero time
+func parseTime(values url.Values, name string) (time.Time, error) {
+	v := values.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be RFC 3339 time", name)
+	}
+
+	return t, nil
+}
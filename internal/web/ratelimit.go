@@ -0,0 +1,98 @@
+package web
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/shalimski/shortener/pkg/clientip"
+	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/shalimski/shortener/pkg/ratelimit"
+	"go.uber.org/zap"
+)
+
+// itemLimit is limiter and client of request charged per item by handler
+type itemLimit struct {
+	limiter ratelimit.Limiter
+	client  string
+}
+
+// RateLimit middleware rejects requests above limit of their client with 429 and Retry-After header.
+// Authenticated requests are limited by api key, others by address of client,
+// requests are allowed while limiter is unavailable
+func RateLimit(limiter ratelimit.Limiter, ips *clientip.Resolver, log *logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			if allow(ctx, w, limiter, rateLimitClient(r, ips), 1, log) {
+				next.ServeHTTP(w, r)
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RateLimitPerItem middleware puts limiter and client of request to context,
+// handler charges the limit by number of items when request body is parsed, see allowItems
+func RateLimitPerItem(limiter ratelimit.Limiter, ips *clientip.Resolver, log *logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), itemLimitCtxKey, itemLimit{
+				limiter: limiter,
+				client:  rateLimitClient(r, ips),
+			})
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// allowItems charges limit put to context by RateLimitPerItem for items of request,
+// it responds 429 and returns false when limit is exceeded, requests without limit are allowed
+func allowItems(ctx context.Context, w http.ResponseWriter, items int, log *logger.Logger) bool {
+	limit, ok := ctx.Value(itemLimitCtxKey).(itemLimit)
+	if !ok {
+		return true
+	}
+
+	return allow(ctx, w, limit.limiter, limit.client, items, log)
+}
+
+// rateLimitClient returns api key of authenticated request or address of client
+func rateLimitClient(r *http.Request, ips *clientip.Resolver) string {
+	if key, ok := authenticated(r.Context()); ok {
+		return "key:" + key.Hash
+	}
+
+	return "ip:" + ips.IP(r)
+}
+
+// allow takes cost tokens of client, it responds 429 and returns false when limit is exceeded
+func allow(ctx context.Context, w http.ResponseWriter, limiter ratelimit.Limiter, client string, cost int, log *logger.Logger) bool {
+	allowed, retryAfter, err := limiter.Allow(ctx, client, cost)
+	if err != nil {
+		log.Error(ctx, "failed to check rate limit", zap.Error(err))
+
+		return true
+	}
+
+	if allowed {
+		return true
+	}
+
+	log.Info(ctx, "rate limit exceeded", zap.String("client", client), zap.Int("cost", cost))
+
+	seconds := int(math.Max(1, math.Ceil(retryAfter.Seconds())))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+
+	if err = Respond(ctx, w, NewResponse("too many requests"), http.StatusTooManyRequests); err != nil {
+		log.Error(ctx, "failed to respond", zap.Error(err))
+	}
+
+	return false
+}
@@ -0,0 +1,73 @@
+// Package bloom implements Bloom filter, a probabilistic set that can report
+// absent value as present but never reports present value as absent
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+type Filter struct {
+	mu     sync.RWMutex
+	bits   []uint64
+	size   uint64 // number of bits
+	hashes uint64 // number of bit positions of every value
+}
+
+// New create filter for expected number of values with target false positive rate,
+// rate grows when more values are added
+func New(capacity int, falsePositiveRate float64) *Filter {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	n := float64(capacity)
+	size := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := uint64(math.Max(1, math.Round(float64(size)/n*math.Ln2)))
+
+	return &Filter{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// positions of value bits are derived from two halves of one 64-bit hash
+func (f *Filter) positions(value string, fn func(pos uint64)) {
+	h := fnv.New64a()
+	h.Write([]byte(value)) //nolint:errcheck // hash never fails
+
+	sum := h.Sum64()
+	h1, h2 := sum&math.MaxUint32, sum>>32
+
+	for i := uint64(0); i < f.hashes; i++ {
+		fn((h1 + i*h2) % f.size)
+	}
+}
+
+// Add value to filter
+func (f *Filter) Add(value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.positions(value, func(pos uint64) {
+		f.bits[pos/64] |= 1 << (pos % 64)
+	})
+}
+
+// MayContain reports false only if value was never added
+func (f *Filter) MayContain(value string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	found := true
+
+	f.positions(value, func(pos uint64) {
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			found = false
+		}
+	})
+
+	return found
+}
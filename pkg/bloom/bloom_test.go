@@ -0,0 +1,33 @@
+package bloom_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/shalimski/shortener/pkg/bloom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	const n = 10000
+
+	f := bloom.New(n, 0.01)
+
+	for i := 0; i < n; i++ {
+		f.Add("in" + strconv.Itoa(i))
+	}
+
+	for i := 0; i < n; i++ {
+		assert.True(t, f.MayContain("in"+strconv.Itoa(i)))
+	}
+
+	falsePositives := 0
+
+	for i := 0; i < n; i++ {
+		if f.MayContain("out" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+
+	assert.Less(t, falsePositives, n*3/100)
+}
@@ -0,0 +1,107 @@
+// Package clientip finds address of client behind trusted reverse proxies
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const forwardedForHeader = "X-Forwarded-For"
+
+// Resolver takes client address from X-Forwarded-For only when request comes from trusted proxy,
+// otherwise header is set by client and can not be believed
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// New create instance of resolver trusting proxies by ip addresses or CIDR ranges
+func New(proxies []string) (*Resolver, error) {
+	r := &Resolver{}
+
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			r.trusted = append(r.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+
+		r.trusted = append(r.trusted, network)
+	}
+
+	return r, nil
+}
+
+// IP returns address of client. Proxies append address of their peer to X-Forwarded-For,
+// so addresses are checked from the last one and the first one not added by trusted proxy is client
+func (r *Resolver) IP(req *http.Request) string {
+	ip := remoteIP(req.RemoteAddr)
+	if !r.isTrusted(ip) {
+		return ip
+	}
+
+	values := req.Header.Values(forwardedForHeader)
+	for i := len(values) - 1; i >= 0; i-- {
+		hops := strings.Split(values[i], ",")
+		for j := len(hops) - 1; j >= 0; j-- {
+			hop := strings.TrimSpace(hops[j])
+			if net.ParseIP(hop) == nil {
+				// malformed entry, the last trusted address is the best known
+				return ip
+			}
+
+			ip = hop
+			if !r.isTrusted(ip) {
+				return ip
+			}
+		}
+	}
+
+	return ip
+}
+
+func (r *Resolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range r.trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteIP strips port of peer address
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
@@ -0,0 +1,61 @@
+package clientip_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shalimski/shortener/pkg/clientip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIP(t *testing.T) {
+	t.Parallel()
+
+	resolver, err := clientip.New([]string{"10.0.0.0/8", "192.168.1.1", ""})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		expected   string
+	}{
+		{"direct client", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"untrusted peer can not forge header", "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"chain of trusted proxies", "10.1.2.3:5000", []string{"198.51.100.1, 192.168.1.1"}, "198.51.100.1"},
+		{"spoofed first entry", "10.1.2.3:5000", []string{"1.1.1.1, 198.51.100.1"}, "198.51.100.1"},
+		{"repeated headers", "10.1.2.3:5000", []string{"1.1.1.1", "198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"only trusted addresses", "10.1.2.3:5000", []string{"10.0.0.2"}, "10.0.0.2"},
+		{"malformed entry", "10.1.2.3:5000", []string{"unknown, 10.0.0.2"}, "10.0.0.2"},
+		{"trusted proxy without header", "192.168.1.1:5000", nil, "192.168.1.1"},
+		{"ipv6 client", "[2001:db8::1]:5000", nil, "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+
+			assert.Equal(t, tt.expected, resolver.IP(r))
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := clientip.New([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = clientip.New([]string{"proxy.local"})
+	assert.Error(t, err)
+}
@@ -10,13 +10,21 @@ import (
 	"google.golang.org/grpc"
 
 	etcd "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
 )
 
 const (
-	locker  = "/locker"
 	counter = "/counter"
+	// legacyLocker is key of lock taken by previous versions around unconditional update of counter,
+	// it is kept until no node of previous version runs, then counter is changed only by compare-and-swap
+	legacyLocker = "/locker"
+	// legacyLockTTL is lifetime of legacy lock in seconds
+	legacyLockTTL = 2
 )
 
+// lockTTL is lifetime of lock in seconds after its holder stops responding
+const lockTTL = 10
+
 type Coordinator struct {
 	cli    *etcd.Client
 	locker lock.Locker
@@ -43,36 +51,81 @@ func NewCoordinator(endpoints []string) (*Coordinator, error) {
 	}, nil
 }
 
+// NextCounter increments counter by compare-and-swap transaction,
+// increment is retried with actual value while other nodes change counter concurrently.
+// Legacy lock is held too, so nodes of previous version overwriting counter without comparison
+// never interleave with the transaction during rolling upgrade
 func (c *Coordinator) NextCounter(ctx context.Context) (int, error) {
-	locker, err := c.locker.WaitAcquire(locker, 2)
+	legacy, err := c.locker.WaitAcquire(legacyLocker, legacyLockTTL)
 	if err != nil {
 		return 0, fmt.Errorf("failed to lock: %w", err)
 	}
 
-	defer locker.Release() //nolint:errcheck // simple
+	defer legacy.Release() //nolint:errcheck // lock expires anyway
 
 	resp, err := c.cli.Get(ctx, counter)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get counter: %w", err)
 	}
 
-	var current int
-	if resp.Count != 0 {
-		current, err = strconv.Atoi(string(resp.Kvs[0].Value))
+	kvs := resp.Kvs
+
+	for {
+		// missing key has zero mod revision
+		var (
+			current  int
+			revision int64
+		)
+
+		if len(kvs) != 0 {
+			current, err = strconv.Atoi(string(kvs[0].Value))
+			if err != nil {
+				return 0, fmt.Errorf("failed to convert counter: %w", err)
+			}
+
+			revision = kvs[0].ModRevision
+		}
+
+		next := current + 1
+
+		txn, err := c.cli.Txn(ctx).
+			If(etcd.Compare(etcd.ModRevision(counter), "=", revision)).
+			Then(etcd.OpPut(counter, strconv.Itoa(next))).
+			Else(etcd.OpGet(counter)).
+			Commit()
 		if err != nil {
-			return 0, fmt.Errorf("failed to convert counter: %w", err)
+			return 0, fmt.Errorf("failed to put counter: %w", err)
 		}
-	}
 
-	next := current + 1
+		if txn.Succeeded {
+			return next, nil
+		}
 
-	_, err = c.cli.Put(ctx, counter, strconv.Itoa(next))
+		// counter was changed by another node, retry with value read by the same transaction
+		kvs = txn.Responses[0].GetResponseRange().Kvs
+	}
+}
 
+// Lock acquires distributed lock by key, it waits while another node holds the lock,
+// unlock releases the lock
+func (c *Coordinator) Lock(ctx context.Context, key string) (unlock func() error, err error) {
+	session, err := concurrency.NewSession(c.cli, concurrency.WithTTL(lockTTL))
 	if err != nil {
-		return 0, fmt.Errorf("failed to put counter: %w", err)
+		return nil, fmt.Errorf("failed to create lock session: %w", err)
+	}
+
+	mu := concurrency.NewMutex(session, key)
+	if err := mu.Lock(ctx); err != nil {
+		session.Close() //nolint:errcheck // already failed
+
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
-	return next, nil
+	return func() error {
+		defer session.Close() //nolint:errcheck // lease expires anyway
+
+		return mu.Unlock(context.Background())
+	}, nil
 }
 
 func (c *Coordinator) Shutdown() {
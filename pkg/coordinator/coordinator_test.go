@@ -0,0 +1,212 @@
+package coordinator_test
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/go-etcd-lock/v5/lock"
+	"github.com/shalimski/shortener/pkg/coordinator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	etcd "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+// startEtcd runs single member etcd in process
+func startEtcd(t *testing.T) string {
+	t.Helper()
+
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+
+	clientURL, _ := url.Parse("http://127.0.0.1:0")
+	peerURL, _ := url.Parse("http://127.0.0.1:0")
+	cfg.LCUrls = []url.URL{*clientURL}
+	cfg.LPUrls = []url.URL{*peerURL}
+
+	etcd, err := embed.StartEtcd(cfg)
+	require.NoError(t, err)
+	t.Cleanup(etcd.Close)
+
+	select {
+	case <-etcd.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		t.Fatal("etcd is not ready")
+	}
+
+	return "http://" + etcd.Clients[0].Addr().String()
+}
+
+func TestNextCounterContention(t *testing.T) {
+	const (
+		nodes    = 8
+		requests = 50
+	)
+
+	endpoint := startEtcd(t)
+	ctx := context.Background()
+
+	var (
+		mu     sync.Mutex
+		values = make(map[int]int)
+		wg     sync.WaitGroup
+	)
+
+	for n := 0; n < nodes; n++ {
+		c, err := coordinator.NewCoordinator([]string{endpoint})
+		require.NoError(t, err)
+
+		defer c.Shutdown()
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < requests; i++ {
+				value, err := c.NextCounter(ctx)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				mu.Lock()
+				values[value]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.Len(t, values, nodes*requests, "every value is unique")
+
+	for value := 1; value <= nodes*requests; value++ {
+		assert.Equal(t, 1, values[value], "value %d", value)
+	}
+}
+
+// legacyNextCounter increments counter like previous versions: unconditional put under lock
+func legacyNextCounter(ctx context.Context, cli *etcd.Client, locker lock.Locker) (int, error) {
+	l, err := locker.WaitAcquire("/locker", 2)
+	if err != nil {
+		return 0, err
+	}
+
+	defer l.Release() //nolint:errcheck // test
+
+	resp, err := cli.Get(ctx, "/counter")
+	if err != nil {
+		return 0, err
+	}
+
+	var current int
+	if resp.Count != 0 {
+		current, err = strconv.Atoi(string(resp.Kvs[0].Value))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := cli.Put(ctx, "/counter", strconv.Itoa(current+1)); err != nil {
+		return 0, err
+	}
+
+	return current + 1, nil
+}
+
+func TestNextCounterRollingUpgrade(t *testing.T) {
+	const requests = 50
+
+	endpoint := startEtcd(t)
+	ctx := context.Background()
+
+	cli, err := etcd.New(etcd.Config{Endpoints: []string{endpoint}})
+	require.NoError(t, err)
+
+	defer cli.Close()
+
+	c, err := coordinator.NewCoordinator([]string{endpoint})
+	require.NoError(t, err)
+
+	defer c.Shutdown()
+
+	var (
+		mu     sync.Mutex
+		values = make(map[int]int)
+		wg     sync.WaitGroup
+	)
+
+	next := []func() (int, error){
+		func() (int, error) { return c.NextCounter(ctx) },
+		func() (int, error) { return legacyNextCounter(ctx, cli, lock.NewEtcdLocker(cli)) },
+	}
+
+	for _, fn := range next {
+		fn := fn
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < requests; i++ {
+				value, err := fn()
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				mu.Lock()
+				values[value]++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, values, 2*requests, "nodes of previous version never get the same value")
+}
+
+func TestLock(t *testing.T) {
+	const nodes = 4
+
+	endpoint := startEtcd(t)
+	ctx := context.Background()
+
+	var (
+		holders int32
+		wg      sync.WaitGroup
+	)
+
+	for n := 0; n < nodes; n++ {
+		c, err := coordinator.NewCoordinator([]string{endpoint})
+		require.NoError(t, err)
+
+		defer c.Shutdown()
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			unlock, err := c.Lock(ctx, "/lock")
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, int32(1), atomic.AddInt32(&holders, 1), "lock is held by one node")
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+
+			assert.NoError(t, unlock())
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,91 @@
+// Package filecounter implements counter persisted to local file,
+// it is an alternative to distributed counter for single node deployments
+package filecounter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const filePerm = 0o600
+
+type Counter struct {
+	mu      sync.Mutex
+	path    string
+	current int
+}
+
+// New loads counter from file, missing file means counter starts from zero
+func New(path string) (*Counter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create counter dir: %w", err)
+	}
+
+	c := &Counter{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read counter: %w", err)
+	}
+
+	c.current, err = strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert counter: %w", err)
+	}
+
+	return c, nil
+}
+
+// NextCounter increments counter, new value is synced to disk before it is returned
+func (c *Counter) NextCounter(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	next := c.current + 1
+
+	if err := c.write(next); err != nil {
+		return 0, fmt.Errorf("failed to put counter: %w", err)
+	}
+
+	c.current = next
+
+	return next, nil
+}
+
+// write replaces counter file atomically, so crash never leaves it partially written
+func (c *Counter) write(value int) error {
+	tmpPath := c.path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.WriteString(strconv.Itoa(value)); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, c.path)
+}
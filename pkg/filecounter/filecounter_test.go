@@ -0,0 +1,33 @@
+package filecounter_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/shalimski/shortener/pkg/filecounter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextCounter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "counter")
+
+	c, err := filecounter.New(path)
+	require.NoError(t, err)
+
+	for want := 1; want <= 3; want++ {
+		got, err := c.NextCounter(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	// counter continues after restart
+	c, err = filecounter.New(path)
+	require.NoError(t, err)
+
+	got, err := c.NextCounter(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, got)
+}
@@ -0,0 +1,61 @@
+// Package filelock takes advisory locks of files shared by processes
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const filePerm = 0o600
+
+// ErrLocked is returned by TryLock when lock is held by another process or another open file
+var ErrLocked = errors.New("file is locked")
+
+// Lock is exclusive lock of lock file, it is held until Unlock or exit of process
+type Lock struct {
+	file *os.File
+}
+
+// TryLock takes exclusive lock of file at path, creates the file if not exist.
+// It does not wait for lock held by another holder and returns ErrLocked
+func TryLock(path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := tryLock(file); err != nil {
+		file.Close()
+
+		return nil, err
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Unlock releases lock, lock file is kept
+func (l *Lock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		l.file.Close()
+
+		return err
+	}
+
+	return l.file.Close()
+}
+
+// Exclusive waits for exclusive lock of open file
+func Exclusive(file *os.File) error {
+	return lock(file, true)
+}
+
+// Shared waits for shared lock of open file, shared locks are held together
+func Shared(file *os.File) error {
+	return lock(file, false)
+}
+
+// Unlock releases lock of open file
+func Unlock(file *os.File) error {
+	return unlock(file)
+}
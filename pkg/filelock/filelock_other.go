@@ -0,0 +1,13 @@
+//go:build !unix
+
+package filelock
+
+import "os"
+
+// files are not locked on platforms without flock, processes must not share them
+
+func tryLock(*os.File) error { return nil }
+
+func lock(*os.File, bool) error { return nil }
+
+func unlock(*os.File) error { return nil }
@@ -0,0 +1,29 @@
+//go:build unix
+
+package filelock_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/shalimski/shortener/pkg/filelock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "LOCK")
+
+	lock, err := filelock.TryLock(path)
+	require.NoError(t, err)
+
+	// every open file is a separate holder, so it works within one process too
+	_, err = filelock.TryLock(path)
+	assert.ErrorIs(t, err, filelock.ErrLocked)
+
+	require.NoError(t, lock.Unlock())
+
+	lock, err = filelock.TryLock(path)
+	require.NoError(t, err)
+	require.NoError(t, lock.Unlock())
+}
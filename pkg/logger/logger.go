@@ -65,7 +65,10 @@ func (l *Logger) With(fields ...zap.Field) {
 	l.zap = newZap
 }
 
-func Middleware(log *Logger) func(next http.Handler) http.Handler {
+// Observer receives result of every request handled by Middleware
+type Observer func(r *http.Request, status int, duration time.Duration)
+
+func Middleware(log *Logger, observers ...Observer) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -85,6 +88,10 @@ func Middleware(log *Logger) func(next http.Handler) http.Handler {
 			}
 
 			log.Info(ctx, http.StatusText(status), fields...)
+
+			for _, observe := range observers {
+				observe(r, status, duration)
+			}
 		}
 
 		return http.HandlerFunc(fn)
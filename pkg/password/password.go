@@ -0,0 +1,32 @@
+// Package password hashes passwords of protected links with bcrypt
+package password
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MaxLength of password in bytes, bcrypt ignores bytes after it
+const MaxLength = 72
+
+var ErrInvalidLength = errors.New("password must be 1 to 72 bytes")
+
+// Hash returns bcrypt hash of password with default cost
+func Hash(password string) (string, error) {
+	if password == "" || len(password) > MaxLength {
+		return "", ErrInvalidLength
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// Verify reports whether password matches hash, comparison takes constant time
+func Verify(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
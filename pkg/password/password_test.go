@@ -0,0 +1,33 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shalimski/shortener/pkg/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	hash, err := password.Hash("secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, "secret", hash)
+
+	assert.True(t, password.Verify(hash, "secret"))
+	assert.False(t, password.Verify(hash, "Secret"))
+	assert.False(t, password.Verify(hash, ""))
+	assert.False(t, password.Verify("", "secret"))
+
+	other, err := password.Hash("secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "hashes are salted")
+
+	_, err = password.Hash("")
+	assert.ErrorIs(t, err, password.ErrInvalidLength)
+
+	_, err = password.Hash(strings.Repeat("a", password.MaxLength+1))
+	assert.ErrorIs(t, err, password.ErrInvalidLength)
+}
@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Attempts counts failed attempts of keys, e.g. wrong passwords, in fixed windows
+type Attempts interface {
+	// Take reserves an attempt of key, it reports false when window of key has no attempts left.
+	// Reserved attempt is counted as failure until it is returned by Forgive
+	Take(ctx context.Context, key string) (bool, error)
+	// Forgive returns attempt reserved by Take when it did not fail
+	Forgive(ctx context.Context, key string) error
+}
+
+type attemptWindow struct {
+	failures int
+	resetAt  time.Time
+}
+
+// MemoryAttempts keeps counters in process memory, so every instance allows its own attempts
+type MemoryAttempts struct {
+	mu     sync.Mutex
+	max    int
+	window time.Duration
+	counts map[string]*attemptWindow
+	now    func() time.Time
+}
+
+var _ Attempts = (*MemoryAttempts)(nil)
+
+// NewMemoryAttempts create instance of in-process counter allowing max failed attempts of key per window
+func NewMemoryAttempts(max int, window time.Duration) *MemoryAttempts {
+	return &MemoryAttempts{
+		max:    max,
+		window: window,
+		counts: make(map[string]*attemptWindow),
+		now:    time.Now,
+	}
+}
+
+func (m *MemoryAttempts) Take(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	w, ok := m.counts[key]
+	if !ok || !now.Before(w.resetAt) {
+		m.sweep(now)
+
+		w = &attemptWindow{resetAt: now.Add(m.window)}
+		m.counts[key] = w
+	}
+
+	if w.failures >= m.max {
+		return false, nil
+	}
+
+	w.failures++
+
+	return true, nil
+}
+
+func (m *MemoryAttempts) Forgive(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if w, ok := m.counts[key]; ok && w.failures > 0 {
+		w.failures--
+	}
+
+	return nil
+}
+
+// sweep drops counters of passed windows when too many keys are counted, caller holds mutex
+func (m *MemoryAttempts) sweep(now time.Time) {
+	if len(m.counts) < sweepSize {
+		return
+	}
+
+	for key, w := range m.counts {
+		if !now.Before(w.resetAt) {
+			delete(m.counts, key)
+		}
+	}
+}
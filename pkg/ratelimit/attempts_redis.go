@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+)
+
+// takeAttemptScript counts attempt in window started by the first attempt, attempt above max is not counted.
+// Result is 1 when attempt is taken, otherwise 0
+var takeAttemptScript = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+if failures == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+if failures > tonumber(ARGV[1]) then
+	redis.call('DECR', KEYS[1])
+	return 0
+end
+return 1
+`)
+
+// forgiveAttemptScript returns attempt of window, missing window is left missing
+var forgiveAttemptScript = redis.NewScript(`
+if (tonumber(redis.call('GET', KEYS[1])) or 0) > 0 then
+	redis.call('DECR', KEYS[1])
+end
+return 1
+`)
+
+// RedisAttempts keeps counters in redis, so attempts are shared by every instance using the same prefix
+type RedisAttempts struct {
+	rdb    *redis.Client
+	prefix string // prefix of counter keys
+	max    int
+	window time.Duration
+}
+
+var _ Attempts = (*RedisAttempts)(nil)
+
+// NewRedisAttempts create instance of distributed counter allowing max failed attempts of key per window
+func NewRedisAttempts(rdb *redis.Client, prefix string, max int, window time.Duration) *RedisAttempts {
+	return &RedisAttempts{
+		rdb:    rdb,
+		prefix: prefix,
+		max:    max,
+		window: window,
+	}
+}
+
+func (r *RedisAttempts) Take(ctx context.Context, key string) (bool, error) {
+	res, err := takeAttemptScript.Run(ctx, r.rdb, []string{r.prefix + key}, r.max, r.window.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return res == 1, nil
+}
+
+func (r *RedisAttempts) Forgive(ctx context.Context, key string) error {
+	return forgiveAttemptScript.Run(ctx, r.rdb, []string{r.prefix + key}).Err()
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryAttempts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMemoryAttempts(2, time.Hour)
+	m.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		ok, err := m.Take(ctx, "a")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	ok, _ := m.Take(ctx, "a")
+	assert.False(t, ok)
+
+	// other keys are not affected
+	ok, _ = m.Take(ctx, "b")
+	assert.True(t, ok)
+
+	// forgiven attempt is not a failure
+	assert.NoError(t, m.Forgive(ctx, "a"))
+
+	ok, _ = m.Take(ctx, "a")
+	assert.True(t, ok)
+
+	ok, _ = m.Take(ctx, "a")
+	assert.False(t, ok)
+
+	// new window has all attempts
+	now = now.Add(time.Hour)
+
+	ok, _ = m.Take(ctx, "a")
+	assert.True(t, ok)
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepSize is number of buckets when full buckets are dropped, full bucket is the same as missing one
+const sweepSize = 10000
+
+type bucket struct {
+	tokens float64
+	last   time.Time // time of last refill
+}
+
+// Memory keeps buckets in process memory, so every instance limits clients separately
+type Memory struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+var _ Limiter = (*Memory)(nil)
+
+// NewMemory create instance of in-process limiter allowing rate requests per second and bursts up to burst requests
+func NewMemory(rate float64, burst int) *Memory {
+	return &Memory{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, cost int) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	b, ok := m.buckets[key]
+	if !ok {
+		m.sweep(now)
+
+		b = &bucket{tokens: m.burst, last: now}
+		m.buckets[key] = b
+	}
+
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(m.burst, b.tokens+elapsed.Seconds()*m.rate)
+		b.last = now
+	}
+
+	need := math.Min(float64(cost), m.burst)
+	if b.tokens < need {
+		return false, refillTime(need-b.tokens, m.rate), nil
+	}
+
+	b.tokens -= float64(cost)
+
+	return true, 0, nil
+}
+
+// sweep drops buckets refilled up to burst when there are too many buckets, caller holds mutex
+func (m *Memory) sweep(now time.Time) {
+	if len(m.buckets) < sweepSize {
+		return
+	}
+
+	for key, b := range m.buckets {
+		if now.Sub(b.last) >= refillTime(m.burst-b.tokens, m.rate) {
+			delete(m.buckets, key)
+		}
+	}
+}
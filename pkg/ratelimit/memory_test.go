@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMemory(2, 3)
+	m.now = func() time.Time { return now }
+
+	// burst is allowed at once
+	for i := 0; i < 3; i++ {
+		allowed, _, err := m.Allow(ctx, "a", 1)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := m.Allow(ctx, "a", 1)
+	assert.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// buckets of other keys are not affected
+	allowed, _, err = m.Allow(ctx, "b", 1)
+	assert.NoError(t, err)
+	assert.True(t, allowed)
+
+	// a token is refilled every half of second
+	now = now.Add(250 * time.Millisecond)
+
+	allowed, retryAfter, _ = m.Allow(ctx, "a", 1)
+	assert.False(t, allowed)
+	assert.Equal(t, 250*time.Millisecond, retryAfter)
+
+	now = now.Add(250 * time.Millisecond)
+
+	allowed, _, _ = m.Allow(ctx, "a", 1)
+	assert.True(t, allowed)
+
+	allowed, _, _ = m.Allow(ctx, "a", 1)
+	assert.False(t, allowed)
+
+	// bucket is not refilled above burst
+	now = now.Add(time.Hour)
+
+	for i := 0; i < 3; i++ {
+		allowed, _, _ = m.Allow(ctx, "a", 1)
+		assert.True(t, allowed)
+	}
+
+	allowed, _, _ = m.Allow(ctx, "a", 1)
+	assert.False(t, allowed)
+}
+
+func TestMemoryCost(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMemory(2, 3)
+	m.now = func() time.Time { return now }
+
+	allowed, _, _ := m.Allow(ctx, "a", 2)
+	assert.True(t, allowed)
+
+	allowed, retryAfter, _ := m.Allow(ctx, "a", 2)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// request costing more than burst needs full bucket and leaves debt
+	now = now.Add(time.Second)
+
+	allowed, _, _ = m.Allow(ctx, "a", 5)
+	assert.True(t, allowed)
+
+	allowed, retryAfter, _ = m.Allow(ctx, "a", 1)
+	assert.False(t, allowed)
+	assert.Equal(t, 1500*time.Millisecond, retryAfter)
+}
+
+func TestMemorySweep(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	m := NewMemory(1, 1)
+	m.now = func() time.Time { return now }
+
+	for i := 0; i < sweepSize; i++ {
+		m.Allow(ctx, fmt.Sprint(i), 1) //nolint:errcheck // memory limiter does not fail
+	}
+
+	assert.Len(t, m.buckets, sweepSize)
+
+	// refilled buckets are dropped when a new key comes
+	now = now.Add(time.Second)
+
+	m.Allow(ctx, "new", 1) //nolint:errcheck // memory limiter does not fail
+	assert.Len(t, m.buckets, 1)
+}
@@ -0,0 +1,22 @@
+// Package ratelimit limits requests of clients by token buckets,
+// every client has a bucket of burst tokens refilled by rate tokens per second and a request takes tokens of its cost.
+// Request costing more than burst is allowed by full bucket and leaves the bucket in debt
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var errUnexpectedResult = errors.New("unexpected result of rate limit script")
+
+type Limiter interface {
+	// Allow takes cost tokens of key bucket, retryAfter is time until bucket has enough tokens when request is not allowed
+	Allow(ctx context.Context, key string, cost int) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// refillTime returns time of refilling tokens at rate per second
+func refillTime(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+)
+
+// takeScript refills bucket by time of redis server, so clocks of nodes do not matter, and takes cost tokens.
+// Bucket is a hash of tokens and time of last refill in microseconds, it expires when it would be full again.
+// Result is 1 when tokens are taken, otherwise 0 and microseconds until bucket has enough tokens
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local need = math.min(cost, burst)
+
+local clock = redis.call('TIME')
+local now = tonumber(clock[1]) * 1000000 + tonumber(clock[2])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(bucket[1]) or burst
+local last = tonumber(bucket[2]) or now
+
+if now > last then
+	tokens = math.min(burst, tokens + (now - last) * rate / 1000000)
+	last = now
+end
+
+local allowed = 0
+local wait = 0
+
+if tokens >= need then
+	tokens = tokens - cost
+	allowed = 1
+else
+	wait = math.ceil((need - tokens) * 1000000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'last', last)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * 1000 / rate) + 1000)
+
+return {allowed, wait}
+`)
+
+// Redis keeps buckets in redis, so limits are shared by every instance using the same prefix
+type Redis struct {
+	rdb    *redis.Client
+	prefix string // prefix of bucket keys, limiters of different endpoints must not share it
+	rate   float64
+	burst  int
+}
+
+var _ Limiter = (*Redis)(nil)
+
+// NewRedis create instance of distributed limiter allowing rate requests per second and bursts up to burst requests
+func NewRedis(rdb *redis.Client, prefix string, rate float64, burst int) *Redis {
+	return &Redis{
+		rdb:    rdb,
+		prefix: prefix,
+		rate:   rate,
+		burst:  burst,
+	}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, cost int) (bool, time.Duration, error) {
+	res, err := takeScript.Run(ctx, r.rdb, []string{r.prefix + key}, r.rate, r.burst, cost).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	if len(res) != 2 {
+		return false, 0, errUnexpectedResult
+	}
+
+	if res[0] == 1 {
+		return true, 0, nil
+	}
+
+	return false, time.Duration(res[1]) * time.Microsecond, nil
+}
@@ -18,13 +18,9 @@ const (
 	URLIP           = `([1-9]\d?|1\d\d|2[01]\d|22[0-3]|24\d|25[0-5])(\.(\d{1,2}|1\d\d|2[0-4]\d|25[0-5])){2}(?:\.([0-9]\d?|1\d\d|2[0-4]\d|25[0-5]))`
 	URLSubdomain    = `((www\.)|([a-zA-Z0-9]+([-_\.]?[a-zA-Z0-9])*[a-zA-Z0-9]\.[a-zA-Z0-9]+))`
 	URL             = `^` + URLSchema + URLUsername + `?` + `((` + URLIP + `|(\[` + IP + `\])|(([a-zA-Z0-9]([a-zA-Z0-9-_]+)?[a-zA-Z0-9]([-\.][a-zA-Z0-9]+)*)|(` + URLSubdomain + `?))?(([a-zA-Z\x{00a1}-\x{ffff}0-9]+-?-?)*[a-zA-Z\x{00a1}-\x{ffff}0-9]+)(?:\.([a-zA-Z\x{00a1}-\x{ffff}]{1,}))?))\.?` + URLPort + `?` + URLPath + `?$`
-	ShortURLSuffix  = `^[A-Za-z0-9]{1,11}$`
 )
 
-var (
-	rxURL      = regexp.MustCompile(URL)
-	rxShortURL = regexp.MustCompile(ShortURLSuffix)
-)
+var rxURL = regexp.MustCompile(URL)
 
 // IsURL checks if the string is an URL.
 func IsURL(str string) bool {
@@ -55,11 +51,27 @@ func IsURL(str string) bool {
 	return rxURL.MatchString(str)
 }
 
-// IsShortURLSuffix check if the string is base62 1-11 string
-func IsShortURLSuffix(str string) bool {
-	if str == "" {
+// IsShortURLSuffix check if the string is made of alphabet characters and is not longer than maxLength,
+// zero maxLength means length is not limited
+func IsShortURLSuffix(str, alphabet string, maxLength int) bool {
+	if str == "" || (maxLength != 0 && len(str) > maxLength) {
 		return false
 	}
 
-	return rxShortURL.MatchString(str)
+	for _, r := range str {
+		if !strings.ContainsRune(alphabet, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// aliasChars are characters of custom aliases, they do not depend on alphabet of generated short urls
+const aliasChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// IsAlias check if the string is a custom alias made of ascii letters and digits and is not longer than maxLength,
+// so aliases stay valid whatever alphabet generates short urls
+func IsAlias(str string, maxLength int) bool {
+	return IsShortURLSuffix(str, aliasChars, maxLength)
 }
@@ -33,20 +33,47 @@ func TestIsURL(t *testing.T) {
 func TestIsShortURLSuffix(t *testing.T) {
 	t.Parallel()
 
+	const base62 = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	tests := []struct {
+		param    string
+		alphabet string
+		expected bool
+	}{
+		{"", base62, false},
+		{"abc", base62, true},
+		{"azip50fke", base62, true},
+		{"-----", base62, false},
+		{"foobar.com", base62, false},
+		{"111111111", base62, true},
+		{"123456789011", base62, false},
+		{"abc", "abcdef", true},
+		{"abcz", "abcdef", false},
+		{"a-b_c", "abc-_", true},
+	}
+	for _, test := range tests {
+		actual := urlvalidator.IsShortURLSuffix(test.param, test.alphabet, 11)
+		assert.Equal(t, test.expected, actual, fmt.Sprintf("IsShortURLSuffix(%q, %q)", test.param, test.alphabet))
+	}
+}
+
+func TestIsAlias(t *testing.T) {
+	t.Parallel()
+
 	tests := []struct {
 		param    string
 		expected bool
 	}{
 		{"", false},
-		{"abc", true},
-		{"azip50fke", true},
-		{"-----", false},
+		{"launch2026", true},
+		{"Promo", true},
+		{"bb-bbbbb", false},
+		{"a_b", false},
 		{"foobar.com", false},
-		{"111111111", true},
 		{"123456789011", false},
 	}
 	for _, test := range tests {
-		actual := urlvalidator.IsShortURLSuffix(test.param)
-		assert.Equal(t, test.expected, actual, fmt.Sprintf("IsShortURLSuffix(%q)", test.param))
+		actual := urlvalidator.IsAlias(test.param, 11)
+		assert.Equal(t, test.expected, actual, fmt.Sprintf("IsAlias(%q)", test.param))
 	}
 }
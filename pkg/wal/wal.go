@@ -0,0 +1,213 @@
+// Package wal implements append-only log of JSON records,
+// every record is synced to disk before Append returns.
+// Log file is locked while it is written or read, so processes may append to the same log,
+// but only one of them may Rewrite it
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/shalimski/shortener/pkg/filelock"
+)
+
+const filePerm = 0o600
+
+type Log struct {
+	mu     sync.Mutex
+	path   string
+	file   *os.File
+	offset int64 // end of last record read
+}
+
+// Open opens log file, creates it and its directory if not exist
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create log dir: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, filePerm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log: %w", err)
+	}
+
+	return &Log{
+		path: path,
+		file: file,
+	}, nil
+}
+
+// Append writes records to the end of log and syncs them once
+func (l *Log) Append(records ...any) error {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := filelock.Exclusive(l.file); err != nil {
+		return fmt.Errorf("failed to lock log: %w", err)
+	}
+
+	defer filelock.Unlock(l.file) //nolint:errcheck // released on close
+
+	if _, err := l.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write log: %w", err)
+	}
+
+	return l.file.Sync()
+}
+
+// Replay calls fn for every record of log from the beginning,
+// incomplete last record left by crash is truncated
+func (l *Log) Replay(fn func(data []byte) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := filelock.Exclusive(l.file); err != nil {
+		return fmt.Errorf("failed to lock log: %w", err)
+	}
+
+	defer filelock.Unlock(l.file) //nolint:errcheck // released on close
+
+	l.offset = 0
+
+	incomplete, err := l.read(fn)
+	if err != nil {
+		return err
+	}
+
+	if incomplete {
+		return l.file.Truncate(l.offset)
+	}
+
+	return nil
+}
+
+// Follow calls fn for records appended after the last Replay or Follow, including records of other processes,
+// incomplete last record is left to be read when it is completed
+func (l *Log) Follow(fn func(data []byte) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := filelock.Shared(l.file); err != nil {
+		return fmt.Errorf("failed to lock log: %w", err)
+	}
+
+	defer filelock.Unlock(l.file) //nolint:errcheck // released on close
+
+	_, err := l.read(fn)
+
+	return err
+}
+
+// read calls fn for complete records after offset and moves offset to the end of them,
+// it reports whether incomplete record is left
+func (l *Log) read(fn func(data []byte) error) (incomplete bool, err error) {
+	if _, err := l.file.Seek(l.offset, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	reader := bufio.NewReader(l.file)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return len(line) != 0, nil
+		}
+
+		if err != nil {
+			return false, fmt.Errorf("failed to read log: %w", err)
+		}
+
+		if err := fn(line); err != nil {
+			return false, err
+		}
+
+		l.offset += int64(len(line))
+	}
+}
+
+// Rewrite atomically replaces content of log with records, used to compact log
+func (l *Log) Rewrite(records []any) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tmpPath := l.path + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create log: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			tmp.Close()
+
+			return fmt.Errorf("failed to write log: %w", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to write log: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to sync log: %w", err)
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, l.path); err != nil {
+		return fmt.Errorf("failed to replace log: %w", err)
+	}
+
+	file, err := os.OpenFile(l.path, os.O_RDWR|os.O_APPEND, filePerm)
+	if err != nil {
+		return fmt.Errorf("failed to open log: %w", err)
+	}
+
+	l.file.Close()
+	l.file = file
+	l.offset = info.Size()
+
+	return nil
+}
+
+// Close log file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
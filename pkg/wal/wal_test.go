@@ -0,0 +1,120 @@
+package wal_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shalimski/shortener/pkg/wal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	Key   string `json:"key"`
+	Value int    `json:"value"`
+}
+
+func replay(t *testing.T, log *wal.Log) []record {
+	t.Helper()
+
+	var records []record
+
+	err := log.Replay(func(data []byte) error {
+		var r record
+		if err := json.Unmarshal(data, &r); err != nil {
+			return err
+		}
+
+		records = append(records, r)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	return records
+}
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "test.wal")
+
+	log, err := wal.Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, log.Append(record{Key: "a", Value: 1}, record{Key: "b", Value: 2}))
+	require.NoError(t, log.Close())
+
+	// torn record left by crash
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"key":"c","val`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	log, err = wal.Open(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, []record{{"a", 1}, {"b", 2}}, replay(t, log))
+
+	require.NoError(t, log.Append(record{Key: "c", Value: 3}))
+	assert.Equal(t, []record{{"a", 1}, {"b", 2}, {"c", 3}}, replay(t, log))
+
+	require.NoError(t, log.Rewrite([]any{record{Key: "b", Value: 3}}))
+	require.NoError(t, log.Append(record{Key: "d", Value: 4}))
+
+	assert.Equal(t, []record{{"b", 3}, {"d", 4}}, replay(t, log))
+	require.NoError(t, log.Close())
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wal")
+
+	log, err := wal.Open(path)
+	require.NoError(t, err)
+
+	defer log.Close()
+
+	require.NoError(t, log.Append(record{Key: "a", Value: 1}))
+	assert.Equal(t, []record{{"a", 1}}, replay(t, log))
+
+	// log opened by another process
+	other, err := wal.Open(path)
+	require.NoError(t, err)
+
+	require.NoError(t, other.Append(record{Key: "b", Value: 2}))
+	require.NoError(t, other.Close())
+
+	// record being written by another process is left until it is completed
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"key":"c",`)
+	require.NoError(t, err)
+
+	follow := func() []record {
+		var records []record
+
+		err := log.Follow(func(data []byte) error {
+			var r record
+			if err := json.Unmarshal(data, &r); err != nil {
+				return err
+			}
+
+			records = append(records, r)
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		return records
+	}
+
+	assert.Equal(t, []record{{"b", 2}}, follow())
+
+	_, err = f.WriteString(`"value":3}` + "\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, []record{{"c", 3}}, follow())
+	assert.Empty(t, follow())
+}
@@ -17,8 +17,12 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/shalimski/shortener/config"
+	"github.com/shalimski/shortener/internal/adapters/repository/keyrepo"
 	"github.com/shalimski/shortener/internal/app"
+	"github.com/shalimski/shortener/internal/services"
 	"github.com/shalimski/shortener/internal/web"
+	"github.com/shalimski/shortener/pkg/logger"
+	"github.com/shalimski/shortener/pkg/mongodb"
 )
 
 func TestRunSuite(t *testing.T) {
@@ -31,6 +35,19 @@ type ShortenerSuit struct {
 	etcdContainer  testcontainers.Container
 	redisContainer testcontainers.Container
 	port           string
+	apiKey         string
+}
+
+// apiKeyTransport authenticates every request of client by api key
+type apiKeyTransport struct {
+	key string
+}
+
+func (t apiKeyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("X-API-Key", t.key)
+
+	return http.DefaultTransport.RoundTrip(r)
 }
 
 func (s *ShortenerSuit) SetupSuite() {
@@ -60,6 +77,8 @@ func (s *ShortenerSuit) SetupSuite() {
 	}
 
 	s.port = cfg.HTTP.Port
+	s.apiKey, err = s.createAPIKey(cfg)
+	s.NoError(err)
 
 	go app.Run(cfg)
 
@@ -73,6 +92,11 @@ func (s *ShortenerSuit) TestFull() {
 		"long_url":"https://commandcenter.blogspot.com/"
 	}`)
 
+	jsonAlias := []byte(`{ 
+		"long_url":"https://commandcenter.blogspot.com/",
+		"alias":"launch2026"
+	}`)
+
 	jsonBad := []byte(`{ 
 		"url":"123"
 	}`)
@@ -83,7 +107,22 @@ func (s *ShortenerSuit) TestFull() {
 
 	api := fmt.Sprintf("http://localhost:%s/api/v1", s.port)
 
-	c := http.Client{}
+	c := http.Client{Transport: apiKeyTransport{key: s.apiKey}}
+
+	s.Run("create unauthorized", func() {
+		r, err := http.Post(api+"/shorten", "application/json", bytes.NewBuffer(jsonOk))
+		s.NoError(err)
+		defer r.Body.Close()
+
+		s.Equal(http.StatusUnauthorized, r.StatusCode)
+
+		var dto web.ResponseMessage
+
+		err = json.NewDecoder(r.Body).Decode(&dto)
+
+		s.NoError(err)
+		s.Equal("invalid api key", dto.Message)
+	})
 
 	s.Run("create ok", func() {
 		r, err := c.Post(api+"/shorten", "application/json", bytes.NewBuffer(jsonOk))
@@ -99,6 +138,35 @@ func (s *ShortenerSuit) TestFull() {
 		s.Equal("b", dto.ShortURL)
 	})
 
+	s.Run("create alias", func() {
+		r, err := c.Post(api+"/shorten", "application/json", bytes.NewBuffer(jsonAlias))
+		s.NoError(err)
+		defer r.Body.Close()
+
+		s.Equal(http.StatusOK, r.StatusCode)
+
+		var dto web.ResponseCreateDTO
+
+		json.NewDecoder(r.Body).Decode(&dto)
+
+		s.Equal("launch2026", dto.ShortURL)
+	})
+
+	s.Run("create alias conflict", func() {
+		r, err := c.Post(api+"/shorten", "application/json", bytes.NewBuffer(jsonAlias))
+		s.NoError(err)
+		defer r.Body.Close()
+
+		s.Equal(http.StatusConflict, r.StatusCode)
+
+		var dto web.ResponseMessage
+
+		err = json.NewDecoder(r.Body).Decode(&dto)
+
+		s.NoError(err)
+		s.Equal("short url already exists", dto.Message)
+	})
+
 	s.Run("create bad", func() {
 		r, err := c.Post(api+"/shorten", "application/json", bytes.NewBuffer(jsonBad))
 		s.NoError(err)
@@ -230,6 +298,21 @@ func (s *ShortenerSuit) TestPing() {
 	})
 }
 
+func (s *ShortenerSuit) createAPIKey(cfg *config.Config) (string, error) {
+	ctx := context.Background()
+
+	mongoClient, err := mongodb.NewClient(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	defer mongoClient.Disconnect(ctx)
+
+	keyRepo := keyrepo.NewKeyRepo(mongoClient.Database(cfg.Mongo.Database))
+
+	return services.NewAuthService(logger.NewTestLogger(), keyRepo).CreateKey(ctx, "integration", false)
+}
+
 func (s *ShortenerSuit) TearDownSuite() {
 	ctx := context.Background()
 	err := s.mongoContainer.Terminate(ctx)